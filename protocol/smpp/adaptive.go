@@ -0,0 +1,114 @@
+package smpp
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/sujit-baniya/smpp/pdu"
+)
+
+// AdaptiveThrottle configures an AIMD controller over the rate limiter of a
+// connection. An ESME_RTHROTTLED response, or a response slower than
+// Latency, multiplies the rate by Decrease; each Interval without such
+// pushback adds Increase back, up to the configured Setting.Throttle.
+type AdaptiveThrottle struct {
+	Min          float64       // lowest rate in messages per second, defaults to 1
+	Increase     float64       // added per Interval, defaults to a tenth of the ceiling
+	Decrease     float64       // multiplier applied on pushback, defaults to 0.5
+	Latency      time.Duration // responses slower than this count as pushback, zero disables
+	Interval     time.Duration // minimum time between two changes, defaults to a second
+	OnRateChange func(connectionID string, rate float64)
+}
+
+// AdaptiveLimiter applies an AdaptiveThrottle to a rate.Limiter.
+type AdaptiveLimiter struct {
+	config  AdaptiveThrottle
+	limiter *rate.Limiter
+	ceiling float64
+	changed time.Time
+	id      string
+	mu      sync.Mutex
+}
+
+// NewAdaptiveLimiter controls limiter, whose current limit is the ceiling.
+func NewAdaptiveLimiter(limiter *rate.Limiter, config AdaptiveThrottle) *AdaptiveLimiter {
+	ceiling := float64(limiter.Limit())
+	if config.Min <= 0 {
+		config.Min = 1
+	}
+	if config.Min > ceiling {
+		config.Min = ceiling
+	}
+	if config.Increase <= 0 {
+		config.Increase = ceiling / 10
+	}
+	if config.Decrease <= 0 || config.Decrease >= 1 {
+		config.Decrease = 0.5
+	}
+	if config.Interval <= 0 {
+		config.Interval = time.Second
+	}
+	return &AdaptiveLimiter{config: config, limiter: limiter, ceiling: ceiling}
+}
+
+// Observe adjusts the rate from the outcome of a request.
+func (a *AdaptiveLimiter) Observe(latency time.Duration, err error) {
+	pushback := errors.Is(err, pdu.ErrThrottled) || a.config.Latency > 0 && latency > a.config.Latency
+	if err != nil && !pushback {
+		return
+	}
+	a.mu.Lock()
+	now := time.Now()
+	if now.Sub(a.changed) < a.config.Interval {
+		a.mu.Unlock()
+		return
+	}
+	current := float64(a.limiter.Limit())
+	next := current
+	if pushback {
+		next = current * a.config.Decrease
+		if next < a.config.Min {
+			next = a.config.Min
+		}
+	} else if current < a.ceiling {
+		next = current + a.config.Increase
+		if next > a.ceiling {
+			next = a.ceiling
+		}
+	}
+	if next != current {
+		a.limiter.SetLimitAt(now, rate.Limit(next))
+	}
+	a.changed = now
+	a.mu.Unlock()
+	if next != current && a.config.OnRateChange != nil {
+		a.config.OnRateChange(a.id, next)
+	}
+}
+
+// Rate returns the effective rate in messages per second.
+func (a *AdaptiveLimiter) Rate() float64 {
+	return float64(a.limiter.Limit())
+}
+
+// Ceiling returns the rate the limiter recovers to.
+func (a *AdaptiveLimiter) Ceiling() float64 {
+	return a.ceiling
+}
+
+// Rates returns the effective send rate per connection ID, for connections
+// with a rate limit.
+func (m *Manager) Rates() map[string]float64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	rates := make(map[string]float64, len(m.connections))
+	for id, conn := range m.connections {
+		if conn.RateLimiter != nil {
+			rates[id] = float64(conn.RateLimiter.Limit())
+		}
+	}
+	return rates
+}
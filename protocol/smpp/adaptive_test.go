@@ -0,0 +1,88 @@
+package smpp_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/sujit-baniya/smpp"
+	"github.com/sujit-baniya/smpp/pdu"
+	"github.com/sujit-baniya/smpp/smpptest"
+)
+
+func TestAdaptiveLimiter(t *testing.T) {
+	limiter := smpp.NewAdaptiveLimiter(rate.NewLimiter(100, 1), smpp.AdaptiveThrottle{
+		Min:      20,
+		Latency:  time.Second,
+		Interval: time.Millisecond,
+	})
+	throttled := &smpp.StatusError{Status: pdu.ErrThrottled}
+	steps := []struct {
+		name    string
+		latency time.Duration
+		err     error
+		rate    float64
+	}{
+		{"throttled", 0, throttled, 50},
+		{"slow", 2 * time.Second, nil, 25},
+		{"at the floor", 0, throttled, 20},
+		{"other error", 0, errors.New("refused"), 20},
+		{"success", 0, nil, 30},
+		{"success", 0, nil, 40},
+	}
+	for _, step := range steps {
+		time.Sleep(2 * time.Millisecond)
+		limiter.Observe(step.latency, step.err)
+		if got := limiter.Rate(); got != step.rate {
+			t.Fatalf("%s: rate %v, want %v", step.name, got, step.rate)
+		}
+	}
+	for i := 0; i < 10; i++ {
+		time.Sleep(2 * time.Millisecond)
+		limiter.Observe(0, nil)
+	}
+	if limiter.Rate() != limiter.Ceiling() {
+		t.Fatalf("rate %v after recovering, want the ceiling %v", limiter.Rate(), limiter.Ceiling())
+	}
+
+	time.Sleep(2 * time.Millisecond)
+	limiter.Observe(0, throttled)
+	limiter.Observe(0, throttled)
+	if limiter.Rate() != 50 {
+		t.Fatalf("rate %v after two pushbacks within an interval, want 50", limiter.Rate())
+	}
+}
+
+func TestAdaptiveThrottle(t *testing.T) {
+	server := newServer(t)
+	changes := make(chan float64, 4)
+	manager := newManager(t, server, smpp.Setting{
+		Throttle: 100,
+		AdaptiveThrottle: &smpp.AdaptiveThrottle{
+			Interval:     time.Millisecond,
+			OnRateChange: func(_ string, rate float64) { changes <- rate },
+		},
+	})
+	server.Inject(smpptest.Throttled)
+	time.Sleep(2 * time.Millisecond) // past the interval started by the bind
+	if _, err := manager.Send(smpp.SendRequest{From: "INFO", To: "491", Message: "pushback"}); !errors.Is(err, pdu.ErrThrottled) {
+		t.Fatal(err)
+	}
+	if rate := <-changes; rate != 50 {
+		t.Fatalf("rate changed to %v, want 50", rate)
+	}
+	for _, rate := range manager.Rates() {
+		if rate != 50 {
+			t.Fatalf("rate %v, want 50", rate)
+		}
+	}
+	time.Sleep(2 * time.Millisecond)
+	if _, err := manager.Send(smpp.SendRequest{From: "INFO", To: "491", Message: "recovered"}); err != nil {
+		t.Fatal(err)
+	}
+	if rate := <-changes; rate != 60 {
+		t.Fatalf("rate changed to %v, want 60", rate)
+	}
+}
@@ -0,0 +1,172 @@
+package smpp
+
+import (
+	"context"
+	"sync"
+)
+
+// Delivery is the outcome of SendAndAwaitDelivery. Receipts holds the final
+// receipt of each segment, nil for those that got none. State rolls them up
+// like a TrackedMessage: delivered once every segment is, failed or expired
+// as soon as one is, and accepted while receipts are missing.
+type Delivery struct {
+	Result   *SendResult
+	State    TrackingState
+	Receipts []*DeliveryReceipt
+}
+
+// maxEarlyReceipts bounds the receipts kept for message IDs not awaited yet.
+const maxEarlyReceipts = 4096
+
+// awaiting dispatches final receipts to the SendAndAwaitDelivery calls in
+// progress. While there is one, receipts for other IDs are kept for a while,
+// since a receipt may be read before the submit_sm_resp of its message.
+type awaiting struct {
+	waiters map[string]chan *DeliveryReceipt
+	early   map[string]*DeliveryReceipt
+	order   []string
+	active  int
+	mu      sync.Mutex
+}
+
+func (a *awaiting) observe(receipt *DeliveryReceipt) {
+	if !receipt.Final() {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if ch, ok := a.waiters[receipt.MessageID]; ok {
+		delete(a.waiters, receipt.MessageID)
+		ch <- receipt
+		return
+	}
+	if a.active == 0 {
+		return
+	}
+	if a.early == nil {
+		a.early = make(map[string]*DeliveryReceipt)
+	}
+	if _, ok := a.early[receipt.MessageID]; !ok {
+		a.order = append(a.order, receipt.MessageID)
+	}
+	a.early[receipt.MessageID] = receipt
+	for len(a.order) > maxEarlyReceipts {
+		delete(a.early, a.order[0])
+		a.order = a.order[1:]
+	}
+}
+
+// begin is called before submitting a message to await.
+func (a *awaiting) begin() {
+	a.mu.Lock()
+	a.active++
+	a.mu.Unlock()
+}
+
+// wait registers the message IDs to await on ch, which must have room for
+// all of them, and hands it the receipts that already arrived.
+func (a *awaiting) wait(ids []string, ch chan *DeliveryReceipt) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.waiters == nil {
+		a.waiters = make(map[string]chan *DeliveryReceipt)
+	}
+	for _, id := range ids {
+		if receipt, ok := a.early[id]; ok {
+			delete(a.early, id)
+			ch <- receipt
+		} else {
+			a.waiters[id] = ch
+		}
+	}
+}
+
+// end forgets the IDs still awaited and, after the last call, the early
+// receipts.
+func (a *awaiting) end(ids []string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, id := range ids {
+		delete(a.waiters, id)
+	}
+	if a.active--; a.active == 0 {
+		a.early, a.order = nil, nil
+	}
+}
+
+// SendAndAwaitDelivery submits req right away, bypassing Setting.Queue, and
+// waits until every accepted segment has a final delivery receipt or ctx
+// ends. A final receipt is requested unless req asks for the default
+// receipts, which include it. The receipts are only observed: they still
+// reach the connection's PDU channel or Setting.OnDeliveryReceipt, which
+// acknowledge them, and ErrReceiptsUnread is returned when neither is read.
+// The error is the one of the submission, even though the accepted segments
+// were awaited, or ctx.Err() when ctx ended first.
+func (m *Manager) SendAndAwaitDelivery(ctx context.Context, req SendRequest, connectionId ...string) (*Delivery, error) {
+	if !m.receiptsRead() {
+		return nil, ErrReceiptsUnread
+	}
+	if req.Options.Receipt != ReceiptDefault {
+		req.Options.Receipt = ReceiptFinal
+	}
+	m.awaiting.begin()
+	result, err := m.send(ctx, "", req, connectionId...)
+	if result == nil {
+		m.awaiting.end(nil)
+		return nil, err
+	}
+	ids := result.MessageIDs()
+	delivery := &Delivery{Result: result, Receipts: make([]*DeliveryReceipt, len(ids))}
+	var awaited []string
+	for _, id := range ids {
+		if id != "" {
+			awaited = append(awaited, id)
+		}
+	}
+	ch := make(chan *DeliveryReceipt, len(awaited))
+	m.awaiting.wait(awaited, ch)
+	defer m.awaiting.end(awaited)
+wait:
+	for pending := len(awaited); pending > 0; pending-- {
+		select {
+		case receipt := <-ch:
+			for i, id := range ids {
+				if id == receipt.MessageID && delivery.Receipts[i] == nil {
+					delivery.Receipts[i] = receipt
+				}
+			}
+		case <-ctx.Done():
+			if err == nil {
+				err = ctx.Err()
+			}
+			break wait
+		}
+	}
+	delivery.rollup()
+	return delivery, err
+}
+
+// receiptsRead reports whether the receipts read on the connections are
+// acknowledged, so that they do not stall them.
+func (m *Manager) receiptsRead() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.setting.OnDeliveryReceipt != nil || m.setting.Store != nil || (m.setting.HandlePDU != nil && m.handling)
+}
+
+func (d *Delivery) rollup() {
+	msg := &TrackedMessage{Segments: make([]TrackedSegment, len(d.Receipts))}
+	for i, receipt := range d.Receipts {
+		segment := &msg.Segments[i]
+		switch {
+		case d.Result.Segments[i].Err != nil:
+			segment.State = TrackingFailed
+		case receipt != nil:
+			segment.State = receiptState(receipt.State)
+		default:
+			segment.State = TrackingAccepted
+		}
+	}
+	msg.rollup()
+	d.State = msg.State
+}
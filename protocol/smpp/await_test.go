@@ -0,0 +1,81 @@
+package smpp_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/sujit-baniya/smpp"
+	"github.com/sujit-baniya/smpp/pdu"
+	"github.com/sujit-baniya/smpp/smpptest"
+)
+
+func awaitDelivery(manager *smpp.Manager, req smpp.SendRequest, timeout time.Duration) (*smpp.Delivery, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return manager.SendAndAwaitDelivery(ctx, req)
+}
+
+func TestSendAndAwaitDelivery(t *testing.T) {
+	server := newServer(t)
+	manager := newManager(t, server, smpp.Setting{})
+	long := smpp.SendRequest{From: "INFO", To: "491", Message: fmt.Sprintf("%0200d", 0)}
+
+	delivery, err := awaitDelivery(manager, long, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if delivery.State != smpp.TrackingDelivered || len(delivery.Receipts) != 2 {
+		t.Fatalf("delivery %+v", delivery)
+	}
+	for i, receipt := range delivery.Receipts {
+		if receipt == nil || receipt.MessageID != delivery.Result.Segments[i].MessageID {
+			t.Fatalf("receipt %d: %+v", i, receipt)
+		}
+	}
+
+	server.ReceiptState = func(*pdu.SubmitSM) pdu.MessageState { return pdu.MessageStateUndeliverable }
+	delivery, err = awaitDelivery(manager, long, 5*time.Second)
+	if err != nil || delivery.State != smpp.TrackingFailed {
+		t.Fatalf("undelivered: %+v, %v", delivery, err)
+	}
+	server.ReceiptState = nil
+
+	// the part accepted is still awaited after the other one failed
+	server.Inject(smpptest.Fault{}, smpptest.Throttled)
+	delivery, err = awaitDelivery(manager, long, 5*time.Second)
+	if !errors.Is(err, pdu.ErrThrottled) || delivery.State != smpp.TrackingFailed {
+		t.Fatalf("partial failure: %+v, %v", delivery, err)
+	}
+	var awaited int
+	for i, segment := range delivery.Result.Segments {
+		if segment.Err == nil && delivery.Receipts[i] != nil {
+			awaited++
+		}
+	}
+	if awaited != 1 {
+		t.Fatalf("%d receipts for the accepted part, want 1", awaited)
+	}
+
+	server.ReceiptDelay = time.Second
+	delivery, err = awaitDelivery(manager, smpp.SendRequest{From: "INFO", To: "491", Message: "slow"}, 100*time.Millisecond)
+	if !errors.Is(err, context.DeadlineExceeded) || delivery.State != smpp.TrackingAccepted {
+		t.Fatalf("receipt pending: %+v, %v", delivery, err)
+	}
+	if delivery.Result.Segments[0].MessageID == "" {
+		t.Fatal("message ID of the accepted part lost")
+	}
+}
+
+func TestSendAndAwaitDeliveryUnread(t *testing.T) {
+	manager, err := smpp.NewManager(smpp.Setting{URL: "localhost:2775"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = manager.SendAndAwaitDelivery(context.Background(), smpp.SendRequest{From: "INFO", To: "491", Message: "hi"})
+	if !errors.Is(err, smpp.ErrReceiptsUnread) {
+		t.Fatalf("got %v, want %v", err, smpp.ErrReceiptsUnread)
+	}
+}
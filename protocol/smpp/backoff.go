@@ -0,0 +1,39 @@
+package smpp
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Backoff computes jittered exponential delays between attempts. The zero
+// value waits between 500ms and 1s for the first attempt, doubling up to a
+// minute.
+type Backoff struct {
+	Initial    time.Duration
+	Max        time.Duration
+	Multiplier float64
+}
+
+// Duration returns the delay before the given attempt, counted from 1. Half
+// of the delay is fixed and the other half is randomized.
+func (b Backoff) Duration(attempt int) time.Duration {
+	initial, max, multiplier := b.Initial, b.Max, b.Multiplier
+	if initial <= 0 {
+		initial = time.Second
+	}
+	if max <= 0 {
+		max = time.Minute
+	}
+	if multiplier < 1 {
+		multiplier = 2
+	}
+	if attempt < 1 {
+		attempt = 1
+	}
+	d := float64(initial) * math.Pow(multiplier, float64(attempt-1))
+	if d > float64(max) {
+		d = float64(max)
+	}
+	return time.Duration(d/2 + rand.Float64()*d/2)
+}
@@ -0,0 +1,56 @@
+package smpp
+
+import (
+	"github.com/sujit-baniya/smpp/pdu"
+)
+
+// BindMode selects which bind operation a session is established with.
+type BindMode byte
+
+const (
+	BindTransceiver BindMode = iota
+	BindTransmitter
+	BindReceiver
+)
+
+func (b BindMode) String() string {
+	switch b {
+	case BindTransmitter:
+		return "transmitter"
+	case BindReceiver:
+		return "receiver"
+	}
+	return "transceiver"
+}
+
+// CanTransmit reports whether an ESME bound in this mode may submit messages.
+func (b BindMode) CanTransmit() bool {
+	return b != BindReceiver
+}
+
+// CanReceive reports whether an ESME bound in this mode may be sent deliver_sm.
+func (b BindMode) CanReceive() bool {
+	return b != BindTransmitter
+}
+
+func readBind(packet interface{}) (mode BindMode, auth Auth, version pdu.InterfaceVersion, ok bool) {
+	switch p := packet.(type) {
+	case *pdu.BindTransceiver:
+		return BindTransceiver, Auth{p.SystemID, p.Password, p.SystemType}, p.Version, true
+	case *pdu.BindTransmitter:
+		return BindTransmitter, Auth{p.SystemID, p.Password, p.SystemType}, p.Version, true
+	case *pdu.BindReceiver:
+		return BindReceiver, Auth{p.SystemID, p.Password, p.SystemType}, p.Version, true
+	}
+	return
+}
+
+func (b BindMode) packet(auth Auth, version pdu.InterfaceVersion) pdu.Responsable {
+	switch b {
+	case BindTransmitter:
+		return &pdu.BindTransmitter{SystemID: auth.SystemID, Password: auth.Password, SystemType: auth.SystemType, Version: version}
+	case BindReceiver:
+		return &pdu.BindReceiver{SystemID: auth.SystemID, Password: auth.Password, SystemType: auth.SystemType, Version: version}
+	}
+	return &pdu.BindTransceiver{SystemID: auth.SystemID, Password: auth.Password, SystemType: auth.SystemType, Version: version}
+}
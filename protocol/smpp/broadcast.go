@@ -0,0 +1,178 @@
+package smpp
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/sujit-baniya/smpp/constant"
+	"github.com/sujit-baniya/smpp/pdu"
+)
+
+// BroadcastRequest is a cell broadcast of a text to areas. The text is sent
+// in message_payload. Only the service type, priority, schedule, validity,
+// data coding and replace options apply.
+type BroadcastRequest struct {
+	MessageID   string // with Options.ReplaceIfPresent, the broadcast to replace
+	From        string
+	Message     string
+	Areas       []pdu.BroadcastArea
+	ContentType pdu.BroadcastContentType
+	Repetitions uint16        // broadcast_rep_num
+	Interval    time.Duration // time between repetitions, zero for as frequently as possible
+	Options     SendOptions
+}
+
+// BroadcastResult is the outcome of a broadcast_sm. When the SMSC refuses
+// the broadcast it may name the areas that failed and why.
+type BroadcastResult struct {
+	MessageID    string
+	ConnectionID string
+	ErrorStatus  pdu.CommandStatus
+	FailedAreas  []pdu.BroadcastArea
+}
+
+// BroadcastStatus is the state of a broadcast reported by
+// query_broadcast_sm. EndTime is zero when the SMSC does not report it.
+type BroadcastStatus struct {
+	MessageID string
+	State     pdu.MessageState
+	Areas     []BroadcastAreaStatus
+	EndTime   time.Time
+}
+
+// BroadcastAreaStatus is the share of an area the broadcast reached, as a
+// percentage or pdu.BroadcastSuccessUnknown.
+type BroadcastAreaStatus struct {
+	Area    pdu.BroadcastArea
+	Success byte
+}
+
+// CancelBroadcastRequest identifies the broadcasts to cancel: the one with
+// MessageID or, when it is empty, every broadcast from From with ServiceType
+// and, if set, ContentType.
+type CancelBroadcastRequest struct {
+	MessageID   string
+	ServiceType string
+	From        string
+	ContentType *pdu.BroadcastContentType
+}
+
+// BroadcastSM submits a cell broadcast. The result is returned along with a
+// StatusError when the SMSC reports failed areas. It is retried according to
+// Setting.Retry, but a broadcast that fails is not handed to OnDeadLetter.
+func (m *Manager) BroadcastSM(ctx context.Context, req BroadcastRequest, connectionId ...string) (*BroadcastResult, error) {
+	if len(req.Areas) == 0 {
+		return nil, ErrNoBroadcastArea
+	}
+	dataCoding := bestCoding(req.Message, m.setting.SmppVersion)
+	if req.Options.DataCoding != nil {
+		dataCoding = *req.Options.DataCoding
+	}
+	segments, err := composePayload(req.Message, dataCoding)
+	if err != nil {
+		return nil, err
+	}
+	packet := &pdu.BroadcastSM{
+		ServiceType:          req.Options.ServiceType,
+		MessageID:            req.MessageID,
+		ScheduleDeliveryTime: smppTime(req.Options.ScheduleAt, req.Options.ScheduleIn),
+		ValidityPeriod:       smppTime(req.Options.ValidUntil, req.Options.ValidFor),
+		SourceAddr:           parseSrcPhone(req.From),
+		DataCoding:           dataCoding,
+		ReplaceIfPresent:     req.Options.ReplaceIfPresent,
+		PriorityFlag:         req.Options.Priority,
+	}
+	for _, area := range req.Areas {
+		packet.Tags.Add(constant.BROADCAST_AREA_IDENTIFIER, area.Bytes())
+	}
+	packet.Tags.Add(constant.BROADCAST_CONTENT_TYPE, req.ContentType.Bytes())
+	_ = packet.Tags.SetUint(constant.BROADCAST_REP_NUM, uint32(req.Repetitions))
+	packet.Tags.Add(constant.BROADCAST_FREQUENCY_INTERVAL, pdu.BroadcastInterval(req.Interval).Bytes())
+	packet.Tags.Add(constant.MESSAGE_PAYLOAD, segments[0].Tags[constant.MESSAGE_PAYLOAD])
+
+	resp, attempts, err := m.retry(ctx, packet, false, connectionId...)
+	var status *StatusError
+	if errors.As(err, &status) {
+		resp = status.Response
+	} else if err != nil {
+		return nil, err
+	}
+	r, ok := resp.(*pdu.BroadcastSMResp)
+	if !ok {
+		if err == nil {
+			err = statusError(resp)
+		}
+		return nil, err
+	}
+	result := &BroadcastResult{MessageID: r.MessageID, ConnectionID: attempts[len(attempts)-1].ConnectionID}
+	if status, err := r.Tags.Uint(constant.BROADCAST_ERROR_STATUS); err == nil {
+		result.ErrorStatus = pdu.CommandStatus(status)
+	}
+	for _, value := range r.Tags.Values(constant.BROADCAST_AREA_IDENTIFIER) {
+		var area pdu.BroadcastArea
+		if area.From(value) == nil {
+			result.FailedAreas = append(result.FailedAreas, area)
+		}
+	}
+	if err == nil {
+		m.remember(result.MessageID, result.ConnectionID)
+	}
+	return result, err
+}
+
+// QueryBroadcastSM asks the SMSC for the state of a broadcast submitted from
+// from, area by area.
+func (m *Manager) QueryBroadcastSM(ctx context.Context, messageID, from string, connectionId ...string) (*BroadcastStatus, error) {
+	ids, err := m.operationConnections(messageID, connectionId)
+	if err != nil {
+		return nil, err
+	}
+	packet := &pdu.QueryBroadcastSM{MessageID: messageID, SourceAddr: parseSrcPhone(from)}
+	resp, err := m.operate(ctx, packet, ids...)
+	if err != nil {
+		return nil, err
+	}
+	r, ok := resp.(*pdu.QueryBroadcastSMResp)
+	if !ok {
+		return nil, statusError(resp)
+	}
+	status := &BroadcastStatus{MessageID: r.MessageID, State: pdu.MessageStateUnknown}
+	if state, err := r.Tags.Uint(constant.DR_MESSAGE_STATE); err == nil {
+		status.State = pdu.MessageState(state)
+	}
+	success := r.Tags.Values(constant.BROADCAST_AREA_SUCCESS)
+	for i, value := range r.Tags.Values(constant.BROADCAST_AREA_IDENTIFIER) {
+		area := BroadcastAreaStatus{Success: pdu.BroadcastSuccessUnknown}
+		if err = area.Area.From(value); err != nil {
+			return nil, err
+		}
+		if i < len(success) && len(success[i]) == 1 {
+			area.Success = success[i][0]
+		}
+		status.Areas = append(status.Areas, area)
+	}
+	var end pdu.Time
+	if value, err := r.Tags.CString(constant.BROADCAST_END_TIME); err == nil && end.From(value) == nil {
+		status.EndTime = end.Time
+	}
+	return status, nil
+}
+
+// CancelBroadcastSM cancels broadcasts.
+func (m *Manager) CancelBroadcastSM(ctx context.Context, req CancelBroadcastRequest, connectionId ...string) error {
+	ids, err := m.operationConnections(req.MessageID, connectionId)
+	if err != nil {
+		return err
+	}
+	packet := &pdu.CancelBroadcastSM{
+		ServiceType: req.ServiceType,
+		MessageID:   req.MessageID,
+		SourceAddr:  parseSrcPhone(req.From),
+	}
+	if req.ContentType != nil {
+		packet.Tags = pdu.Tags{constant.BROADCAST_CONTENT_TYPE: req.ContentType.Bytes()}
+	}
+	_, err = m.operate(ctx, packet, ids...)
+	return err
+}
@@ -0,0 +1,101 @@
+package smpp_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sujit-baniya/smpp"
+	"github.com/sujit-baniya/smpp/coding"
+	"github.com/sujit-baniya/smpp/constant"
+	"github.com/sujit-baniya/smpp/pdu"
+	"github.com/sujit-baniya/smpp/smpptest"
+)
+
+func TestBroadcast(t *testing.T) {
+	server := newServer(t)
+	north, south := pdu.NamedBroadcastArea("north"), pdu.NamedBroadcastArea("south")
+	server.AreaStatus = func(area pdu.BroadcastArea) pdu.CommandStatus {
+		if area.String() == "south" {
+			return pdu.ErrSystemError
+		}
+		return 0
+	}
+	letters := make(chan smpp.DeadLetter, 2)
+	manager := newManager(t, server, smpp.Setting{
+		Retry: smpp.RetryPolicy{
+			MaxAttempts:  2,
+			Backoff:      smpp.Backoff{Initial: time.Millisecond},
+			OnDeadLetter: func(letter smpp.DeadLetter) { letters <- letter },
+		},
+	})
+	ctx := context.Background()
+	if _, err := manager.BroadcastSM(ctx, smpp.BroadcastRequest{From: "INFO", Message: "alert"}); !errors.Is(err, smpp.ErrNoBroadcastArea) {
+		t.Fatalf("broadcast without an area: %v", err)
+	}
+
+	latin1 := coding.Latin1Coding
+	req := smpp.BroadcastRequest{
+		From:        "INFO",
+		Message:     "alert",
+		Areas:       []pdu.BroadcastArea{north, south},
+		Repetitions: 3,
+		Options:     smpp.SendOptions{DataCoding: &latin1},
+	}
+	result, err := manager.BroadcastSM(ctx, req)
+	if !errors.Is(err, pdu.ErrBroadcastFailed) {
+		t.Fatalf("broadcast to a failing area: %v", err)
+	}
+	if result == nil || result.ErrorStatus != pdu.ErrSystemError || len(result.FailedAreas) != 1 || result.FailedAreas[0].String() != "south" {
+		t.Fatalf("failed broadcast %+v", result)
+	}
+	if n := len(server.Broadcasts()); n != 1 {
+		t.Fatalf("%d broadcast_sm, want a failed broadcast not retried", n)
+	}
+
+	req.Areas = []pdu.BroadcastArea{north}
+	result, err = manager.BroadcastSM(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := server.Broadcasts()[1]
+	if string(p.Tags.Get(constant.MESSAGE_PAYLOAD)) != "alert" || len(p.Tags.Values(constant.BROADCAST_AREA_IDENTIFIER)) != 1 {
+		t.Fatalf("broadcast_sm tags %v", p.Tags)
+	}
+	if repetitions, _ := p.Tags.Uint(constant.BROADCAST_REP_NUM); repetitions != 3 {
+		t.Fatalf("broadcast_rep_num %d", repetitions)
+	}
+	status, err := manager.QueryBroadcastSM(ctx, result.MessageID, "INFO")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.State != pdu.MessageStateEnroute || len(status.Areas) != 1 || status.Areas[0].Area.String() != "north" || status.Areas[0].Success != 100 || !status.EndTime.IsZero() {
+		t.Fatalf("status of a running broadcast %+v", status)
+	}
+	if err = manager.CancelBroadcastSM(ctx, smpp.CancelBroadcastRequest{MessageID: result.MessageID}); err != nil {
+		t.Fatal(err)
+	}
+	if status, err = manager.QueryBroadcastSM(ctx, result.MessageID, "INFO"); err != nil {
+		t.Fatal(err)
+	}
+	if status.State != pdu.MessageStateDeleted || status.EndTime.IsZero() {
+		t.Fatalf("status of a cancelled broadcast %+v", status)
+	}
+	if err = manager.CancelBroadcastSM(ctx, smpp.CancelBroadcastRequest{MessageID: result.MessageID}); !errors.Is(err, pdu.ErrBroadcastCancelFailed) {
+		t.Fatalf("second cancel: %v", err)
+	}
+
+	server.Inject(smpptest.Throttled, smpptest.Throttled)
+	if _, err = manager.BroadcastSM(ctx, req); !errors.Is(err, pdu.ErrThrottled) {
+		t.Fatalf("throttled broadcast: %v", err)
+	}
+	if n := len(server.Broadcasts()); n != 4 {
+		t.Fatalf("%d broadcast_sm, want the throttled one retried once", n)
+	}
+	select {
+	case letter := <-letters:
+		t.Fatalf("broadcast dead-lettered %+v", letter)
+	default:
+	}
+}
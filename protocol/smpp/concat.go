@@ -0,0 +1,117 @@
+package smpp
+
+import (
+	"encoding/binary"
+
+	"github.com/sujit-baniya/smpp/coding"
+	"github.com/sujit-baniya/smpp/constant"
+	"github.com/sujit-baniya/smpp/pdu"
+)
+
+// Concatenation selects how a text longer than one short message is sent.
+type Concatenation byte
+
+const (
+	ConcatenateUDH     Concatenation = iota // segments with a concatenation user data header
+	ConcatenateSAR                          // segments with the sar_* TLVs
+	ConcatenatePayload                      // one PDU with the whole text in message_payload
+)
+
+// MaxMessagePayloadLength is the longest encoded text sent in message_payload.
+// The whole submit_sm must fit in a 64 KiB PDU, which leaves room for the
+// header, the mandatory fields at their longest and the TLV header. Other TLVs
+// set through SendOptions count against the same limit when marshalled.
+const MaxMessagePayloadLength = 0x10000 - maxSubmitSMOverhead
+
+// maxSubmitSMOverhead is the size of a submit_sm without short_message and
+// TLVs: header, service_type, both addresses, eight single octets and the two
+// times, plus the header of the message_payload TLV.
+const maxSubmitSMOverhead = 16 + 6 + 2*(2+21) + 8 + 2*17 + 4
+
+// Segment is the short message and TLVs of one submitted PDU.
+type Segment struct {
+	Message pdu.ShortMessage
+	Tags    pdu.Tags
+}
+
+func (c Concatenation) String() string {
+	switch c {
+	case ConcatenateSAR:
+		return "sar"
+	case ConcatenatePayload:
+		return "payload"
+	}
+	return "udh"
+}
+
+// ComposeSegments encodes input with dataCoding and splits it as mode
+// requires. Texts fitting in one short message are sent as is, except with
+// ConcatenatePayload which always uses message_payload.
+func ComposeSegments(input string, dataCoding coding.DataCoding, mode Concatenation, reference uint16) (segments []Segment, err error) {
+	switch mode {
+	case ConcatenatePayload:
+		return composePayload(input, dataCoding)
+	case ConcatenateSAR:
+		return composeSAR(input, dataCoding, reference)
+	}
+	parts, err := pdu.ComposeMultipartShortMessage(input, dataCoding, reference)
+	for _, part := range parts {
+		segments = append(segments, Segment{Message: part})
+	}
+	return
+}
+
+func composePayload(input string, dataCoding coding.DataCoding) ([]Segment, error) {
+	if dataCoding.Encoding() == nil {
+		return nil, pdu.ErrUnknownDataCoding
+	}
+	payload, err := dataCoding.Encoding().NewEncoder().Bytes([]byte(input))
+	if err != nil {
+		return nil, err
+	}
+	if len(payload) > MaxMessagePayloadLength {
+		return nil, ErrPayloadTooLong
+	}
+	return []Segment{{
+		Message: pdu.ShortMessage{DataCoding: dataCoding},
+		Tags:    pdu.Tags{constant.MESSAGE_PAYLOAD: payload},
+	}}, nil
+}
+
+func composeSAR(input string, dataCoding coding.DataCoding, reference uint16) (segments []Segment, err error) {
+	splitter, encoding := dataCoding.Splitter(), dataCoding.Encoding()
+	if splitter == nil || encoding == nil {
+		return nil, pdu.ErrUnknownDataCoding
+	}
+	if splitter.Len(input) <= pdu.MaxShortMessageLength {
+		return ComposeSegments(input, dataCoding, ConcatenateUDH, reference)
+	}
+	parts := splitter.Split(input, pdu.MaxShortMessageLength)
+	if len(parts) > 0xFF {
+		return nil, pdu.ErrMultipartTooMuch
+	}
+	ref := make([]byte, 2)
+	binary.BigEndian.PutUint16(ref, reference)
+	encoder := encoding.NewEncoder()
+	for i, part := range parts {
+		encoder.Reset()
+		message := pdu.ShortMessage{DataCoding: dataCoding}
+		if message.Message, err = encoder.Bytes([]byte(part)); err != nil {
+			return nil, err
+		}
+		segments = append(segments, Segment{Message: message, Tags: pdu.Tags{
+			constant.SAR_MSG_REF_NUM:    ref,
+			constant.SAR_TOTAL_SEGMENTS: {byte(len(parts))},
+			constant.SAR_SEGMENT_SEQNUM: {byte(i + 1)},
+		}})
+	}
+	return
+}
+
+// bestCoding picks the data coding of a text for the negotiated version.
+func bestCoding(input string, smppVersion pdu.InterfaceVersion) coding.DataCoding {
+	if smppVersion == pdu.SMPPVersion50 {
+		return coding.BestCoding(input)
+	}
+	return coding.BestSafeCoding(input)
+}
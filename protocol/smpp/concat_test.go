@@ -0,0 +1,97 @@
+package smpp_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/sujit-baniya/smpp"
+	"github.com/sujit-baniya/smpp/coding"
+	"github.com/sujit-baniya/smpp/constant"
+)
+
+func TestConcatenateSAR(t *testing.T) {
+	server := newServer(t)
+	manager := newManager(t, server, smpp.Setting{Concatenation: smpp.ConcatenateSAR})
+	text := strings.Repeat("abcdefghij", 40)
+	if _, err := manager.Send(smpp.SendRequest{From: "INFO", To: "491", Message: text}); err != nil {
+		t.Fatal(err)
+	}
+	submitted := server.Submitted()
+	if len(submitted) != 3 {
+		t.Fatalf("%d submit_sm, want 3", len(submitted))
+	}
+	parts := make([]string, 3)
+	reference := submitted[0].Tags[constant.SAR_MSG_REF_NUM]
+	for _, p := range submitted {
+		if p.ESMClass.UDHIndicator || p.Message.UDHeader != nil {
+			t.Fatal("SAR segment with a user data header")
+		}
+		if !bytes.Equal(p.Tags[constant.SAR_MSG_REF_NUM], reference) || len(reference) != 2 {
+			t.Fatalf("sar_msg_ref_num %x, want %x", p.Tags[constant.SAR_MSG_REF_NUM], reference)
+		}
+		if total := p.Tags[constant.SAR_TOTAL_SEGMENTS]; !bytes.Equal(total, []byte{3}) {
+			t.Fatalf("sar_total_segments %x", total)
+		}
+		seqnum := p.Tags[constant.SAR_SEGMENT_SEQNUM]
+		if len(seqnum) != 1 || seqnum[0] < 1 || seqnum[0] > 3 {
+			t.Fatalf("sar_segment_seqnum %x", seqnum)
+		}
+		parts[seqnum[0]-1], _ = p.Message.Parse()
+	}
+	if got := strings.Join(parts, ""); got != text {
+		t.Fatalf("reassembled %q", got)
+	}
+
+	if _, err := manager.Send(smpp.SendRequest{From: "INFO", To: "491", Message: "short"}); err != nil {
+		t.Fatal(err)
+	}
+	if p := server.Submitted()[3]; len(p.Tags) != 0 {
+		t.Fatalf("single short message with tags %v", p.Tags)
+	}
+}
+
+func TestConcatenatePayload(t *testing.T) {
+	server := newServer(t)
+	manager := newManager(t, server, smpp.Setting{Concatenation: smpp.ConcatenatePayload})
+	// Latin-1 encodes one octet per character, filling the payload exactly
+	latin1 := coding.Latin1Coding
+	options := smpp.SendOptions{DataCoding: &latin1}
+	text := strings.Repeat("a", smpp.MaxMessagePayloadLength)
+	result, err := manager.Send(smpp.SendRequest{From: "INFO", To: "491", Message: text, Options: options})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Segments) != 1 {
+		t.Fatalf("%d segments, want 1", len(result.Segments))
+	}
+	p := server.Submitted()[0]
+	if len(p.Message.Message) != 0 || p.Message.UDHeader != nil {
+		t.Fatalf("short_message of %d octets next to message_payload", len(p.Message.Message))
+	}
+	if payload := p.Tags[constant.MESSAGE_PAYLOAD]; string(payload) != text {
+		t.Fatalf("message_payload of %d octets does not hold the text", len(payload))
+	}
+
+	_, err = manager.Send(smpp.SendRequest{From: "INFO", To: "491", Message: text + "a", Options: options})
+	if !errors.Is(err, smpp.ErrPayloadTooLong) {
+		t.Fatalf("payload over the limit: %v", err)
+	}
+	if n := len(server.Submitted()); n != 1 {
+		t.Fatalf("%d submit_sm, want the oversized one refused", n)
+	}
+}
+
+func TestComposeSegmentsReference(t *testing.T) {
+	segments, err := smpp.ComposeSegments(strings.Repeat("x", 200), 0, smpp.ConcatenateSAR, 0xBEEF)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, segment := range segments {
+		if ref := binary.BigEndian.Uint16(segment.Tags[constant.SAR_MSG_REF_NUM]); ref != 0xBEEF {
+			t.Fatalf("reference %x, want beef", ref)
+		}
+	}
+}
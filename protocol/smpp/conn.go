@@ -1,13 +1,15 @@
 package smpp
 
 import (
+	"bufio"
 	"context"
+	"encoding/binary"
 	"errors"
 	"github.com/sujit-baniya/smpp/pdu"
 	"golang.org/x/time/rate"
 	"io"
-	"math/rand"
 	"net"
+	"sync"
 	"time"
 
 	"github.com/rs/xid"
@@ -18,19 +20,43 @@ type ConnectionInterface interface {
 	Throttle() error
 }
 
+type response struct {
+	packet interface{}
+	err    error
+}
+
+// receiptBacklog is the number of receipts read ahead of OnDeliveryReceipt.
+const receiptBacklog = 256
+
+type pendingReceipt struct {
+	receipt *DeliveryReceipt
+	resp    interface{}
+}
+
 type Conn struct {
-	parent       net.Conn
-	ctx          context.Context
-	cancel       context.CancelFunc
-	receiveQueue chan interface{}
-	pending      map[int32]func(interface{})
-	ID           string
-	NextSequence func() int32
-	ReadTimeout  time.Duration
-	WriteTimeout time.Duration
-	RateLimiter  *rate.Limiter
-	rwctx        context.Context
-	lmctx        context.Context
+	parent            net.Conn
+	ctx               context.Context
+	cancel            context.CancelFunc
+	receiveQueue      chan interface{}
+	pending           *pendingRequests
+	ID                string
+	Mode              BindMode
+	outbind           bool
+	NextSequence      func() int32
+	ReadTimeout       time.Duration
+	WriteTimeout      time.Duration
+	ResponseTimeout   time.Duration
+	OnTimeout         func(err *TimeoutError)
+	OnDeliveryReceipt func(receipt *DeliveryReceipt) error
+	observeReceipt    func(receipt *DeliveryReceipt)
+	receipts          chan pendingReceipt
+	RateLimiter       *rate.Limiter
+	Window            *Window
+	Adaptive          *AdaptiveLimiter
+	rwctx             context.Context
+	lmctx             context.Context
+	err               error
+	mu                sync.Mutex
 }
 
 func OpenConn(ctx context.Context, smsc string, throttle int) (conn *Conn, err error) {
@@ -45,15 +71,16 @@ func NewConn(ctx context.Context, parent net.Conn, throttle int) *Conn {
 	ctx, cancel := context.WithCancel(ctx)
 
 	conn := &Conn{
-		parent:       parent,
-		ctx:          ctx,
-		cancel:       cancel,
-		receiveQueue: make(chan interface{}),
-		pending:      make(map[int32]func(interface{})),
-		ID:           xid.New().String(),
-		NextSequence: rand.Int31,
-		ReadTimeout:  time.Minute * 15,
-		WriteTimeout: time.Minute * 15,
+		parent:          parent,
+		ctx:             ctx,
+		cancel:          cancel,
+		receiveQueue:    make(chan interface{}),
+		pending:         newPendingRequests(),
+		ID:              xid.New().String(),
+		NextSequence:    NewSequence(),
+		ReadTimeout:     time.Minute * 15,
+		WriteTimeout:    time.Minute * 15,
+		ResponseTimeout: time.Minute * 15,
 	}
 	if throttle != 0 {
 		rateLimiter := rate.NewLimiter(rate.Limit(throttle), 1)
@@ -65,9 +92,15 @@ func NewConn(ctx context.Context, parent net.Conn, throttle int) *Conn {
 
 //goland:noinspection SpellCheckingInspection
 func (c *Conn) Watch() {
-	defer c.cancel()
 	var err error
-	var packet interface{}
+	defer close(c.receiveQueue)
+	defer func() { c.fail(err) }()
+	reader := bufio.NewReader(c.parent)
+	go c.expirePending()
+	if c.OnDeliveryReceipt != nil {
+		c.receipts = make(chan pendingReceipt, receiptBacklog)
+		go c.handleReceipts()
+	}
 	for {
 		select {
 		case <-c.ctx.Done():
@@ -77,25 +110,121 @@ func (c *Conn) Watch() {
 		if c.ReadTimeout > 0 {
 			_ = c.parent.SetReadDeadline(time.Now().Add(c.ReadTimeout))
 		}
-		var status pdu.CommandStatus
-		if packet, err = pdu.ReadPDU(c.parent); errors.Is(err, io.EOF) {
+		var header []byte
+		if header, err = reader.Peek(16); err != nil {
 			return
-		} else if ok := errors.Is(err, status); err != nil {
-			if packet == nil {
+		}
+		commandID := pdu.CommandID(binary.BigEndian.Uint32(header[4:8]))
+		sequence := int32(binary.BigEndian.Uint32(header[12:16]))
+		var packet interface{}
+		if packet, err = pdu.ReadPDU(reader); err != nil {
+			if packet == nil && !errors.Is(err, pdu.ErrInvalidCommandID) {
 				return
-			} else if !ok {
+			} else if commandID.IsResponse() {
+				c.resolve(sequence, nil, err)
+				continue
+			}
+			var status pdu.CommandStatus
+			if !errors.As(err, &status) {
 				status = pdu.ErrUnknownError
 			}
-			sequence := pdu.ReadSequence(packet)
 			_ = c.Send(&pdu.GenericNACK{
 				Header: pdu.Header{CommandStatus: status, Sequence: sequence},
 				Tags:   pdu.Tags{0xFFFF: []byte(err.Error())},
 			})
 			continue
-		} else if callback, ok := c.pending[pdu.ReadSequence(packet)]; ok {
-			callback(packet)
-		} else {
-			c.receiveQueue <- packet
+		}
+		if unbind, ok := packet.(*pdu.Unbind); ok {
+			_ = c.Send(unbind.Resp())
+			err = ErrUnbound
+			return
+		} else if commandID.IsResponse() {
+			// a response nobody waits for any more, to a request that timed
+			// out or was cancelled, is dropped rather than left to block
+			// the read loop until PDU is read
+			c.resolve(sequence, packet, nil)
+			continue
+		} else if c.receipt(packet) {
+			continue
+		}
+		select {
+		case c.receiveQueue <- packet:
+		case <-c.ctx.Done():
+			return
+		}
+	}
+}
+
+// receipt hands a delivery receipt to OnDeliveryReceipt, when set, instead of
+// queueing it for PDU. The handler runs on its own goroutine, so that it may
+// block or submit on this connection while responses are still read; only
+// once receiptBacklog receipts await it does Watch stop reading.
+func (c *Conn) receipt(packet interface{}) bool {
+	if c.receipts == nil && c.observeReceipt == nil {
+		return false
+	}
+	receipt, err := ParseDeliveryReceipt(packet)
+	if err != nil {
+		return false
+	}
+	receipt.ConnectionID = c.ID
+	if c.observeReceipt != nil {
+		c.observeReceipt(receipt)
+	}
+	if c.receipts == nil {
+		return false
+	}
+	select {
+	case c.receipts <- pendingReceipt{receipt: receipt, resp: packet.(pdu.Responsable).Resp()}:
+	case <-c.ctx.Done():
+	}
+	return true
+}
+
+// handleReceipts runs OnDeliveryReceipt on the receipts in the order they
+// were read and acknowledges each once the handler returns, with the status
+// of its error if any.
+func (c *Conn) handleReceipts() {
+	for {
+		select {
+		case <-c.ctx.Done():
+			return
+		case pending := <-c.receipts:
+			if err := c.OnDeliveryReceipt(pending.receipt); err != nil {
+				pdu.WriteCommandStatus(pending.resp, commandStatusOf(err, pdu.ErrTemporaryAppError))
+			}
+			_ = c.Send(pending.resp)
+		}
+	}
+}
+
+func (c *Conn) resolve(sequence int32, packet interface{}, err error) bool {
+	return c.pending.resolve(sequence, packet, err)
+}
+
+// expirePending fails requests left unanswered for longer than
+// ResponseTimeout with a TimeoutError and reports them to OnTimeout.
+func (c *Conn) expirePending() {
+	tick := c.ResponseTimeout / 4
+	if tick < 10*time.Millisecond {
+		tick = 10 * time.Millisecond
+	} else if tick > time.Second {
+		tick = time.Second
+	}
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.ctx.Done():
+			return
+		case now := <-ticker.C:
+			for _, request := range c.pending.expire(now) {
+				err := &TimeoutError{ConnectionID: c.ID, Header: request.header, Duration: c.ResponseTimeout}
+				request.callback(nil, err)
+				if c.OnTimeout != nil {
+					c.OnTimeout(err)
+				}
+			}
 		}
 	}
 }
@@ -104,21 +233,53 @@ func (c *Conn) Bind(ctx context.Context, packet pdu.Responsable) (resp interface
 	return c.Submit(ctx, packet)
 }
 
+// Submit sends packet and waits for its response. Unless it is an
+// enquire_link or unbind, the request holds a slot of Window until then. A
+// response with a non-zero command status, or a generic_nack, is returned
+// along with a *StatusError. A response arriving after ctx ended or the
+// request expired is dropped.
 func (c *Conn) Submit(ctx context.Context, packet pdu.Responsable) (resp interface{}, err error) {
+	var control bool
+	switch packet.(type) {
+	case *pdu.EnquireLink, *pdu.Unbind:
+		control = true
+	}
+	if c.Window != nil && !control {
+		if err = c.Window.Acquire(ctx); err != nil {
+			return
+		}
+		defer c.Window.Release()
+	}
+	returns := make(chan response, 1)
+	callback := func(packet interface{}, err error) { returns <- response{packet, err} }
+	var expires time.Time
+	if c.ResponseTimeout > 0 {
+		expires = time.Now().Add(c.ResponseTimeout)
+	}
 	sequence := c.NextSequence()
 	pdu.WriteSequence(packet, sequence)
+	for !c.pending.add(sequence, packet, expires, callback) {
+		sequence = c.NextSequence()
+		pdu.WriteSequence(packet, sequence)
+	}
+	defer c.pending.remove(sequence)
+	start := time.Now()
 	if err = c.Send(packet); err != nil {
 		return
 	}
-	returns := make(chan interface{}, 1)
-	c.pending[sequence] = func(resp interface{}) { returns <- resp }
-	defer delete(c.pending, sequence)
+	if c.Adaptive != nil && !control {
+		defer func() { c.Adaptive.Observe(time.Since(start), err) }()
+	}
 	select {
 	case <-c.ctx.Done():
 		err = ErrConnectionClosed
 	case <-ctx.Done():
 		err = ctx.Err()
-	case resp = <-returns:
+	case r := <-returns:
+		resp, err = r.packet, r.err
+		if err == nil {
+			err = statusError(resp)
+		}
 	}
 	return
 }
@@ -135,7 +296,7 @@ func (c *Conn) Send(packet interface{}) (err error) {
 	if err == nil {
 		_, err = pdu.Marshal(c.parent, packet)
 	}
-	if errors.Is(err, io.EOF) {
+	if errors.Is(err, io.EOF) || errors.Is(err, net.ErrClosed) {
 		err = ErrConnectionClosed
 	}
 	return
@@ -144,38 +305,103 @@ func (c *Conn) Send(packet interface{}) (err error) {
 func (c *Conn) EnquireLink(tick time.Duration, timeout time.Duration) {
 	ticker := time.NewTicker(tick)
 	defer ticker.Stop()
-	sendEnquireLink := func() {
+	sendEnquireLink := func() error {
 		ctx, cancel := context.WithTimeout(c.ctx, timeout)
 		defer cancel()
-		if _, err := c.Submit(ctx, new(pdu.EnquireLink)); err != nil {
-			ticker.Stop()
-			_ = c.Close()
+		_, err := c.Submit(ctx, new(pdu.EnquireLink))
+		var status *StatusError
+		if errors.As(err, &status) {
+			// any answer proves the link is alive
+			return nil
 		}
+		return err
 	}
 	for {
-		sendEnquireLink()
-		<-ticker.C
+		if err := sendEnquireLink(); err != nil {
+			c.setErr(err)
+			_ = c.Close()
+			return
+		}
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-ticker.C:
+		}
 	}
 }
 
+// Close unbinds the session and closes the underlying connection; the
+// connection is closed even when the unbind is not acknowledged.
 func (c *Conn) Close() (err error) {
+	defer c.terminate()
+	if c.ctx.Err() != nil {
+		return
+	}
 	ctx, cancel := context.WithTimeout(c.ctx, time.Second)
 	defer cancel()
-	defer c.cancel()
-	if _, err = c.Submit(ctx, new(pdu.Unbind)); err == nil {
-		close(c.receiveQueue)
-		err = c.parent.Close()
-	}
+	_, err = c.Submit(ctx, new(pdu.Unbind))
 	return
 }
 
+func (c *Conn) terminate() {
+	c.fail(ErrConnectionClosed)
+}
+
+func (c *Conn) fail(err error) {
+	c.setErr(err)
+	c.cancel()
+	_ = c.parent.Close()
+}
+
+func (c *Conn) setErr(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err == nil {
+		c.err = err
+	}
+}
+
+// Err reports why the connection ended, or nil while it is still open.
+func (c *Conn) Err() error {
+	if c.ctx.Err() == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
 func (c *Conn) Done() <-chan struct{} {
 	return c.ctx.Done()
 }
 
 func (c *Conn) Throttle() error {
-	if c.RateLimiter != nil {
-		return c.RateLimiter.Wait(c.rwctx)
+	return c.ThrottleContext(c.rwctx)
+}
+
+// ThrottleContext waits until RateLimiter allows a request, ctx ends or the
+// connection closes.
+func (c *Conn) ThrottleContext(ctx context.Context) error {
+	if c.RateLimiter == nil {
+		return nil
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-c.ctx.Done():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	if err := c.RateLimiter.Wait(ctx); err != nil {
+		if c.ctx.Err() != nil {
+			return ErrConnectionClosed
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return err
 	}
 	return nil
 }
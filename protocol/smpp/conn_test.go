@@ -0,0 +1,53 @@
+package smpp_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sujit-baniya/smpp"
+	"github.com/sujit-baniya/smpp/smpptest"
+)
+
+func TestLateResponseAfterTimeout(t *testing.T) {
+	server := newServer(t)
+	timeouts := make(chan *smpp.TimeoutError, 1)
+	manager := newManager(t, server, smpp.Setting{
+		ResponseTimeout: 100 * time.Millisecond,
+		OnTimeout:       func(err *smpp.TimeoutError) { timeouts <- err },
+	})
+	server.Inject(smpptest.Delayed(300 * time.Millisecond))
+	if _, err := manager.Send(smpp.SendRequest{From: "INFO", To: "491", Message: "late"}); !errors.Is(err, smpp.ErrResponseTimeout) {
+		t.Fatalf("got %v, want %v", err, smpp.ErrResponseTimeout)
+	}
+	timeout := <-timeouts
+	if timeout.Header.Sequence == 0 || timeout.Header.CommandID.String() != "submit_sm" {
+		t.Fatalf("timeout of %s", timeout)
+	}
+	// let the late submit_sm_resp arrive before sending again
+	time.Sleep(300 * time.Millisecond)
+	for i := 0; i < 3; i++ {
+		if _, err := manager.Send(smpp.SendRequest{From: "INFO", To: "491", Message: "next"}); err != nil {
+			t.Fatalf("send %d after a late response: %v", i, err)
+		}
+	}
+}
+
+func TestLateResponseAfterCancel(t *testing.T) {
+	server := newServer(t)
+	manager := newManager(t, server, smpp.Setting{})
+	server.Inject(smpptest.Delayed(300 * time.Millisecond))
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	if _, err := manager.SendContext(ctx, smpp.SendRequest{From: "INFO", To: "491", Message: "cancelled"}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("got %v, want %v", err, context.Canceled)
+	}
+	// let the late submit_sm_resp arrive before sending again
+	time.Sleep(300 * time.Millisecond)
+	for i := 0; i < 3; i++ {
+		if _, err := manager.Send(smpp.SendRequest{From: "INFO", To: "491", Message: "next"}); err != nil {
+			t.Fatalf("send %d after a late response: %v", i, err)
+		}
+	}
+}
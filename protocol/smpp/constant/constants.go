@@ -20,54 +20,73 @@ const (
 
 const (
 	// ESME Error Constants
-	ESME_ROK              CMDStatus = 0x00000000 // OK!
-	ESME_RINVMSGLEN       CMDStatus = 0x00000001 // Message Length is invalid
-	ESME_RINVCMDLEN       CMDStatus = 0x00000002 // Command Length is invalid
-	ESME_RINVCMDID        CMDStatus = 0x00000003 // Invalid Command ID
-	ESME_RINVBNDSTS       CMDStatus = 0x00000004 // Incorrect BIND Status for given command
-	ESME_RALYBND          CMDStatus = 0x00000005 // ESME Already in Bound State
-	ESME_RINVPRTFLG       CMDStatus = 0x00000006 // Invalid Priority Flag
-	ESME_RINVREGDLVFLG    CMDStatus = 0x00000007 // Invalid Registered Delivery Flag
-	ESME_RSYSERR          CMDStatus = 0x00000008 // System Error
-	ESME_RINVSRCADR       CMDStatus = 0x0000000A // Invalid Source Address
-	ESME_RINVDSTADR       CMDStatus = 0x0000000B // Invalid Dest Addr
-	ESME_RINVMSGID        CMDStatus = 0x0000000C // Message ID is invalid
-	ESME_RBINDFAIL        CMDStatus = 0x0000000D // Bind Failed
-	ESME_RINVPASWD        CMDStatus = 0x0000000E // Invalid Password
-	ESME_RINVSYSID        CMDStatus = 0x0000000F // Invalid System ID
-	ESME_RCANCELFAIL      CMDStatus = 0x00000011 // Cancel SM Failed
-	ESME_RREPLACEFAIL     CMDStatus = 0x00000013 // Replace SM Failed
-	ESME_RMSGQFUL         CMDStatus = 0x00000014 // Message Queue Full
-	ESME_RINVSERTYP       CMDStatus = 0x00000015 // Invalid Service Type
-	ESME_RINVNUMDESTS     CMDStatus = 0x00000033 // Invalid number of destinations
-	ESME_RINVDLNAME       CMDStatus = 0x00000034 // Invalid Distribution List name
-	ESME_RINVDESTFLAG     CMDStatus = 0x00000040 // Destination flag is invalid
-	ESME_RINVSUBREP       CMDStatus = 0x00000042 // Invalid 'submit with replace' request
-	ESME_RINVESMCLASS     CMDStatus = 0x00000043 // Invalid esm_class field data
-	ESME_RCNTSUBDL        CMDStatus = 0x00000044 // Cannot Submit to Distribution List
-	ESME_RSUBMITFAIL      CMDStatus = 0x00000045 // submit_sm or submit_multi failed
-	ESME_RINVSRCTON       CMDStatus = 0x00000048 // Invalid Source address TON
-	ESME_RINVSRCNPI       CMDStatus = 0x00000049 // Invalid Source address NPI
-	ESME_RINVDSTTON       CMDStatus = 0x00000050 // Invalid Destination address TON
-	ESME_RINVDSTNPI       CMDStatus = 0x00000051 // Invalid Destination address NPI
-	ESME_RINVSYSTYP       CMDStatus = 0x00000053 // Invalid system_type field
-	ESME_RINVREPFLAG      CMDStatus = 0x00000054 // Invalid replace_if_present flag
-	ESME_RINVNUMMSGS      CMDStatus = 0x00000055 // Invalid number of messages
-	ESME_RTHROTTLED       CMDStatus = 0x00000058 // Throttling error
-	ESME_RINVSCHED        CMDStatus = 0x00000061 // Invalid Scheduled Delivery Time
-	ESME_RINVEXPIRY       CMDStatus = 0x00000062 // Invalid message validity period (Expiry time)
-	ESME_RINVDFTMSGID     CMDStatus = 0x00000063 // Predefined Message Invalid or Not Found
-	ESME_RX_T_APPN        CMDStatus = 0x00000064 // ESME Receiver Temporary App Error Code
-	ESME_RX_P_APPN        CMDStatus = 0x00000065 // ESME Receiver Permanent App Error Code
-	ESME_RX_R_APPN        CMDStatus = 0x00000066 // ESME Receiver Reject Message Error Code
-	ESME_RQUERYFAIL       CMDStatus = 0x00000067 // Query_sm request failed
-	ESME_RINVOPTPARSTREAM CMDStatus = 0x000000C0 // Error in the optional part of the PDU Body
-	ESME_ROPTPARNOTALLWD  CMDStatus = 0x000000C1 // Optional Parameter not allowed
-	ESME_RINVPARLEN       CMDStatus = 0x000000C2 // Invalid Parameter Length
-	ESME_RMISSINGOPTPARAM CMDStatus = 0x000000C3 // Expected Optional Parameter missing
-	ESME_RINVOPTPARAMVAL  CMDStatus = 0x000000C4 // Invalid Optional Parameter Value
-	ESME_RDELIVERYFAILURE CMDStatus = 0x000000FE // Delivery Failure (used for data_sm_resp)
-	ESME_RUNKNOWNERR      CMDStatus = 0x000000FF // Unknown Error
+	ESME_ROK                 CMDStatus = 0x00000000 // OK!
+	ESME_RINVMSGLEN          CMDStatus = 0x00000001 // Message Length is invalid
+	ESME_RINVCMDLEN          CMDStatus = 0x00000002 // Command Length is invalid
+	ESME_RINVCMDID           CMDStatus = 0x00000003 // Invalid Command ID
+	ESME_RINVBNDSTS          CMDStatus = 0x00000004 // Incorrect BIND Status for given command
+	ESME_RALYBND             CMDStatus = 0x00000005 // ESME Already in Bound State
+	ESME_RINVPRTFLG          CMDStatus = 0x00000006 // Invalid Priority Flag
+	ESME_RINVREGDLVFLG       CMDStatus = 0x00000007 // Invalid Registered Delivery Flag
+	ESME_RSYSERR             CMDStatus = 0x00000008 // System Error
+	ESME_RINVSRCADR          CMDStatus = 0x0000000A // Invalid Source Address
+	ESME_RINVDSTADR          CMDStatus = 0x0000000B // Invalid Dest Addr
+	ESME_RINVMSGID           CMDStatus = 0x0000000C // Message ID is invalid
+	ESME_RBINDFAIL           CMDStatus = 0x0000000D // Bind Failed
+	ESME_RINVPASWD           CMDStatus = 0x0000000E // Invalid Password
+	ESME_RINVSYSID           CMDStatus = 0x0000000F // Invalid System ID
+	ESME_RCANCELFAIL         CMDStatus = 0x00000011 // Cancel SM Failed
+	ESME_RREPLACEFAIL        CMDStatus = 0x00000013 // Replace SM Failed
+	ESME_RMSGQFUL            CMDStatus = 0x00000014 // Message Queue Full
+	ESME_RINVSERTYP          CMDStatus = 0x00000015 // Invalid Service Type
+	ESME_RINVNUMDESTS        CMDStatus = 0x00000033 // Invalid number of destinations
+	ESME_RINVDLNAME          CMDStatus = 0x00000034 // Invalid Distribution List name
+	ESME_RINVDESTFLAG        CMDStatus = 0x00000040 // Destination flag is invalid
+	ESME_RINVSUBREP          CMDStatus = 0x00000042 // Invalid 'submit with replace' request
+	ESME_RINVESMCLASS        CMDStatus = 0x00000043 // Invalid esm_class field data
+	ESME_RCNTSUBDL           CMDStatus = 0x00000044 // Cannot Submit to Distribution List
+	ESME_RSUBMITFAIL         CMDStatus = 0x00000045 // submit_sm or submit_multi failed
+	ESME_RINVSRCTON          CMDStatus = 0x00000048 // Invalid Source address TON
+	ESME_RINVSRCNPI          CMDStatus = 0x00000049 // Invalid Source address NPI
+	ESME_RINVDSTTON          CMDStatus = 0x00000050 // Invalid Destination address TON
+	ESME_RINVDSTNPI          CMDStatus = 0x00000051 // Invalid Destination address NPI
+	ESME_RINVSYSTYP          CMDStatus = 0x00000053 // Invalid system_type field
+	ESME_RINVREPFLAG         CMDStatus = 0x00000054 // Invalid replace_if_present flag
+	ESME_RINVNUMMSGS         CMDStatus = 0x00000055 // Invalid number of messages
+	ESME_RTHROTTLED          CMDStatus = 0x00000058 // Throttling error
+	ESME_RINVSCHED           CMDStatus = 0x00000061 // Invalid Scheduled Delivery Time
+	ESME_RINVEXPIRY          CMDStatus = 0x00000062 // Invalid message validity period (Expiry time)
+	ESME_RINVDFTMSGID        CMDStatus = 0x00000063 // Predefined Message Invalid or Not Found
+	ESME_RX_T_APPN           CMDStatus = 0x00000064 // ESME Receiver Temporary App Error Code
+	ESME_RX_P_APPN           CMDStatus = 0x00000065 // ESME Receiver Permanent App Error Code
+	ESME_RX_R_APPN           CMDStatus = 0x00000066 // ESME Receiver Reject Message Error Code
+	ESME_RQUERYFAIL          CMDStatus = 0x00000067 // Query_sm request failed
+	ESME_RINVOPTPARSTREAM    CMDStatus = 0x000000C0 // Error in the optional part of the PDU Body
+	ESME_ROPTPARNOTALLWD     CMDStatus = 0x000000C1 // Optional Parameter not allowed
+	ESME_RINVPARLEN          CMDStatus = 0x000000C2 // Invalid Parameter Length
+	ESME_RMISSINGOPTPARAM    CMDStatus = 0x000000C3 // Expected Optional Parameter missing
+	ESME_RINVOPTPARAMVAL     CMDStatus = 0x000000C4 // Invalid Optional Parameter Value
+	ESME_RDELIVERYFAILURE    CMDStatus = 0x000000FE // Delivery Failure (used for data_sm_resp)
+	ESME_RUNKNOWNERR         CMDStatus = 0x000000FF // Unknown Error
+	ESME_RSERTYPUNAUTH       CMDStatus = 0x00000100 // ESME Not authorised to use specified service_type
+	ESME_RPROHIBITED         CMDStatus = 0x00000101 // ESME Prohibited from using specified operation
+	ESME_RSERTYPUNAVAIL      CMDStatus = 0x00000102 // Specified service_type is unavailable
+	ESME_RSERTYPDENIED       CMDStatus = 0x00000103 // Specified service_type is denied
+	ESME_RINVDCS             CMDStatus = 0x00000104 // Invalid Data Coding Scheme
+	ESME_RINVSRCADDRSUBUNIT  CMDStatus = 0x00000105 // Source Address Sub unit is Invalid
+	ESME_RINVDSTADDRSUBUNIT  CMDStatus = 0x00000106 // Destination Address Sub unit is Invalid
+	ESME_RINVBCASTFREQINT    CMDStatus = 0x00000107 // Broadcast Frequency Interval is invalid
+	ESME_RINVBCASTALIAS_NAME CMDStatus = 0x00000108 // Broadcast Alias Name is invalid
+	ESME_RINVBCASTAREAFMT    CMDStatus = 0x00000109 // Broadcast Area Format is invalid
+	ESME_RINVNUMBCAST_AREAS  CMDStatus = 0x0000010A // Number of Broadcast Areas is invalid
+	ESME_RINVBCASTCNTTYPE    CMDStatus = 0x0000010B // Broadcast Content Type is invalid
+	ESME_RINVBCASTMSGCLASS   CMDStatus = 0x0000010C // Broadcast Message Class is invalid
+	ESME_RBCASTFAIL          CMDStatus = 0x0000010D // broadcast_sm operation failed
+	ESME_RBCASTQUERYFAIL     CMDStatus = 0x0000010E // query_broadcast_sm operation failed
+	ESME_RBCASTCANCELFAIL    CMDStatus = 0x0000010F // cancel_broadcast_sm operation failed
+	ESME_RINVBCAST_REP       CMDStatus = 0x00000110 // Number of Repeated Broadcasts is invalid
+	ESME_RINVBCASTSRVGRP     CMDStatus = 0x00000111 // Broadcast Service Group is invalid
+	ESME_RINVBCASTCHANIND    CMDStatus = 0x00000112 // Broadcast Channel Indicator is invalid
 )
 
 const (
@@ -136,50 +155,70 @@ const (
 
 const (
 	// Optional Field Tags
-	DEST_ADDR_SUBUNIT           = 0x0005
-	DEST_NETWORK_TYPE           = 0x0006
-	DEST_BEARER_TYPE            = 0x0007
-	DEST_TELEMATICS_ID          = 0x0008
-	SOURCE_ADDR_SUBUNIT         = 0x000D
-	SOURCE_NETWORK_TYPE         = 0x000E
-	SOURCE_BEARER_TYPE          = 0x000F
-	SOURCE_TELEMATICS_ID        = 0x0010
-	QOS_TIME_TO_LIVE            = 0x0017
-	PAYLOAD_TYPE                = 0x0019
-	ADDITIONAL_STATUS_INFO_TEXT = 0x001D
-	RECEIPTED_MESSAGE_ID        = 0x001E
-	MS_MSG_WAIT_FACILITIES      = 0x0030
-	PRIVACY_INDICATOR           = 0x0201
-	SOURCE_SUBADDRESS           = 0x0202
-	DEST_SUBADDRESS             = 0x0203
-	USER_MESSAGE_REFERENCE      = 0x0204
-	USER_RESPONSE_CODE          = 0x0205
-	SOURCE_PORT                 = 0x020A
-	DESTINATION_PORT            = 0x020B
-	SAR_MSG_REF_NUM             = 0x020C
-	LANGUAGE_INDICATOR          = 0x020D
-	SAR_TOTAL_SEGMENTS          = 0x020E
-	SAR_SEGMENT_SEQNUM          = 0x020F
-	SC_INTERFACE_VERSION        = 0x0210
-	CALLBACK_NUM_PRES_IND       = 0x0302
-	CALLBACK_NUM_ATAG           = 0x0303
-	NUMBER_OF_MESSAGES          = 0x0304
-	CALLBACK_NUM                = 0x0381
-	DPF_RESULT                  = 0x0420
-	SET_DPF                     = 0x0421
-	MS_AVAILABILITY_STATUS      = 0x0422
-	NETWORK_ERROR_CODE          = 0x0423
-	MESSAGE_PAYLOAD             = 0x0424
-	DELIVERY_FAILURE_REASON     = 0x0425
-	MORE_MESSAGES_TO_SEND       = 0x0426
-	DR_MESSAGE_STATE            = 0x0427
-	USSD_SERVICE_OP             = 0x0501
-	DISPLAY_TIME                = 0x1201
-	SMS_SIGNAL                  = 0x1203
-	MS_VALIDITY                 = 0x1204
-	ALERT_ON_MESSAGE_DELIVERY   = 0x130C
-	ITS_REPLY_TYPE              = 0x1380
-	ITS_SESSION_INFO            = 0x1383
+	DEST_ADDR_SUBUNIT            = 0x0005
+	DEST_NETWORK_TYPE            = 0x0006
+	DEST_BEARER_TYPE             = 0x0007
+	DEST_TELEMATICS_ID           = 0x0008
+	SOURCE_ADDR_SUBUNIT          = 0x000D
+	SOURCE_NETWORK_TYPE          = 0x000E
+	SOURCE_BEARER_TYPE           = 0x000F
+	SOURCE_TELEMATICS_ID         = 0x0010
+	QOS_TIME_TO_LIVE             = 0x0017
+	PAYLOAD_TYPE                 = 0x0019
+	ADDITIONAL_STATUS_INFO_TEXT  = 0x001D
+	RECEIPTED_MESSAGE_ID         = 0x001E
+	MS_MSG_WAIT_FACILITIES       = 0x0030
+	PRIVACY_INDICATOR            = 0x0201
+	SOURCE_SUBADDRESS            = 0x0202
+	DEST_SUBADDRESS              = 0x0203
+	USER_MESSAGE_REFERENCE       = 0x0204
+	USER_RESPONSE_CODE           = 0x0205
+	SOURCE_PORT                  = 0x020A
+	DESTINATION_PORT             = 0x020B
+	SAR_MSG_REF_NUM              = 0x020C
+	LANGUAGE_INDICATOR           = 0x020D
+	SAR_TOTAL_SEGMENTS           = 0x020E
+	SAR_SEGMENT_SEQNUM           = 0x020F
+	SC_INTERFACE_VERSION         = 0x0210
+	CALLBACK_NUM_PRES_IND        = 0x0302
+	CALLBACK_NUM_ATAG            = 0x0303
+	NUMBER_OF_MESSAGES           = 0x0304
+	CALLBACK_NUM                 = 0x0381
+	DPF_RESULT                   = 0x0420
+	SET_DPF                      = 0x0421
+	MS_AVAILABILITY_STATUS       = 0x0422
+	NETWORK_ERROR_CODE           = 0x0423
+	MESSAGE_PAYLOAD              = 0x0424
+	DELIVERY_FAILURE_REASON      = 0x0425
+	MORE_MESSAGES_TO_SEND        = 0x0426
+	DR_MESSAGE_STATE             = 0x0427
+	CONGESTION_STATE             = 0x0428
+	USSD_SERVICE_OP              = 0x0501
+	BROADCAST_CHANNEL_INDICATOR  = 0x0600
+	BROADCAST_CONTENT_TYPE       = 0x0601
+	BROADCAST_CONTENT_TYPE_INFO  = 0x0602
+	BROADCAST_MESSAGE_CLASS      = 0x0603
+	BROADCAST_REP_NUM            = 0x0604
+	BROADCAST_FREQUENCY_INTERVAL = 0x0605
+	BROADCAST_AREA_IDENTIFIER    = 0x0606
+	BROADCAST_ERROR_STATUS       = 0x0607
+	BROADCAST_AREA_SUCCESS       = 0x0608
+	BROADCAST_END_TIME           = 0x0609
+	BROADCAST_SERVICE_GROUP      = 0x060A
+	BILLING_IDENTIFICATION       = 0x060B
+	SOURCE_NETWORK_ID            = 0x060D
+	DEST_NETWORK_ID              = 0x060E
+	SOURCE_NODE_ID               = 0x060F
+	DEST_NODE_ID                 = 0x0610
+	DEST_ADDR_NP_RESOLUTION      = 0x0611
+	DEST_ADDR_NP_INFORMATION     = 0x0612
+	DEST_ADDR_NP_COUNTRY         = 0x0613
+	DISPLAY_TIME                 = 0x1201
+	SMS_SIGNAL                   = 0x1203
+	MS_VALIDITY                  = 0x1204
+	ALERT_ON_MESSAGE_DELIVERY    = 0x130C
+	ITS_REPLY_TYPE               = 0x1380
+	ITS_SESSION_INFO             = 0x1383
 )
 
 const (
@@ -348,7 +387,7 @@ func (s CMDStatus) Error() string {
 	case ESME_RINVNUMMSGS:
 		return fmt.Sprint("Invalid number of messages")
 	case ESME_RTHROTTLED:
-		return fmt.Sprint("Throttling error (ESME has exceeded allowed message limit")
+		return fmt.Sprint("Throttling error (ESME has exceeded allowed message limit)")
 	case ESME_RINVSCHED:
 		return fmt.Sprint("Invalid Scheduled Delivery Time")
 	case ESME_RINVEXPIRY:
@@ -377,5 +416,43 @@ func (s CMDStatus) Error() string {
 		return fmt.Sprint("Delivery Failure (used for data_sm_resp)")
 	case ESME_RUNKNOWNERR:
 		return fmt.Sprint("Unknown Error")
+	case ESME_RSERTYPUNAUTH:
+		return fmt.Sprint("ESME Not authorised to use specified service_type")
+	case ESME_RPROHIBITED:
+		return fmt.Sprint("ESME Prohibited from using specified operation")
+	case ESME_RSERTYPUNAVAIL:
+		return fmt.Sprint("Specified service_type is unavailable")
+	case ESME_RSERTYPDENIED:
+		return fmt.Sprint("Specified service_type is denied")
+	case ESME_RINVDCS:
+		return fmt.Sprint("Invalid Data Coding Scheme")
+	case ESME_RINVSRCADDRSUBUNIT:
+		return fmt.Sprint("Source Address Sub unit is Invalid")
+	case ESME_RINVDSTADDRSUBUNIT:
+		return fmt.Sprint("Destination Address Sub unit is Invalid")
+	case ESME_RINVBCASTFREQINT:
+		return fmt.Sprint("Broadcast Frequency Interval is invalid")
+	case ESME_RINVBCASTALIAS_NAME:
+		return fmt.Sprint("Broadcast Alias Name is invalid")
+	case ESME_RINVBCASTAREAFMT:
+		return fmt.Sprint("Broadcast Area Format is invalid")
+	case ESME_RINVNUMBCAST_AREAS:
+		return fmt.Sprint("Number of Broadcast Areas is invalid")
+	case ESME_RINVBCASTCNTTYPE:
+		return fmt.Sprint("Broadcast Content Type is invalid")
+	case ESME_RINVBCASTMSGCLASS:
+		return fmt.Sprint("Broadcast Message Class is invalid")
+	case ESME_RBCASTFAIL:
+		return fmt.Sprint("broadcast_sm operation failed")
+	case ESME_RBCASTQUERYFAIL:
+		return fmt.Sprint("query_broadcast_sm operation failed")
+	case ESME_RBCASTCANCELFAIL:
+		return fmt.Sprint("cancel_broadcast_sm operation failed")
+	case ESME_RINVBCAST_REP:
+		return fmt.Sprint("Number of Repeated Broadcasts is invalid")
+	case ESME_RINVBCASTSRVGRP:
+		return fmt.Sprint("Broadcast Service Group is invalid")
+	case ESME_RINVBCASTCHANIND:
+		return fmt.Sprint("Broadcast Channel Indicator is invalid")
 	}
 }
@@ -0,0 +1,96 @@
+package smpp
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sujit-baniya/smpp/constant"
+	"github.com/sujit-baniya/smpp/pdu"
+)
+
+// NewSequence returns a generator of sequence numbers that increase
+// monotonically from SEQUENCE_NUM_START and wrap after SEQUENCE_NUM_END. It is
+// safe for concurrent use.
+func NewSequence() func() int32 {
+	var sequence uint32
+	return func() int32 {
+		for {
+			current := atomic.LoadUint32(&sequence)
+			next := current + 1
+			if next > constant.SEQUENCE_NUM_END {
+				next = constant.SEQUENCE_NUM_START
+			}
+			if atomic.CompareAndSwapUint32(&sequence, current, next) {
+				return int32(next)
+			}
+		}
+	}
+}
+
+type pendingRequest struct {
+	header   pdu.Header
+	callback func(interface{}, error)
+	expires  time.Time
+}
+
+// pendingRequests correlates responses with the requests awaiting them by
+// sequence number.
+type pendingRequests struct {
+	requests map[int32]pendingRequest
+	mu       sync.Mutex
+}
+
+func newPendingRequests() *pendingRequests {
+	return &pendingRequests{requests: make(map[int32]pendingRequest)}
+}
+
+// add registers callback for sequence and reports false when the sequence is
+// still awaiting another response.
+func (p *pendingRequests) add(sequence int32, packet pdu.Responsable, expires time.Time, callback func(interface{}, error)) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.requests[sequence]; ok {
+		return false
+	}
+	header := pdu.Header{CommandID: pdu.ReadCommandID(packet), Sequence: sequence}
+	p.requests[sequence] = pendingRequest{header: header, callback: callback, expires: expires}
+	return true
+}
+
+func (p *pendingRequests) remove(sequence int32) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.requests, sequence)
+}
+
+// resolve removes the request for sequence and hands it the response.
+func (p *pendingRequests) resolve(sequence int32, packet interface{}, err error) bool {
+	p.mu.Lock()
+	request, ok := p.requests[sequence]
+	delete(p.requests, sequence)
+	p.mu.Unlock()
+	if ok {
+		request.callback(packet, err)
+	}
+	return ok
+}
+
+// expire removes and returns every request whose expiry is before now.
+func (p *pendingRequests) expire(now time.Time) (expired []pendingRequest) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for sequence, request := range p.requests {
+		if !request.expires.IsZero() && request.expires.Before(now) {
+			expired = append(expired, request)
+			delete(p.requests, sequence)
+		}
+	}
+	return
+}
+
+func (p *pendingRequests) Len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.requests)
+}
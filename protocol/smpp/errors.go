@@ -1,7 +1,112 @@
 package smpp
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sujit-baniya/smpp/constant"
+	"github.com/sujit-baniya/smpp/pdu"
+)
 
 var (
 	ErrConnectionClosed = errors.New("smpp: connection closed")
+	ErrUnbound          = errors.New("smpp: unbound by peer")
+	ErrServerClosed     = errors.New("smpp: server closed")
+	ErrSkipResponse     = errors.New("smpp: skip response")
+	ErrNotOutbind       = errors.New("smpp: expected outbind")
+	ErrTooManySessions  = errors.New("smpp: too many sessions")
+	ErrWindowFull       = errors.New("smpp: window full")
+	ErrResponseTimeout  = errors.New("smpp: response timeout")
+	ErrNoQueue          = errors.New("smpp: no queue configured")
+	ErrQueueClosed      = errors.New("smpp: queue closed")
+	ErrNoConnection     = errors.New("smpp: no bound connection available")
+	ErrSegmentSkipped   = errors.New("smpp: segment not sent after an earlier one failed")
+	ErrNoRecipient      = errors.New("smpp: message has no recipient")
+	ErrPayloadTooLong   = errors.New("smpp: message payload too long")
+	ErrReplaceTooLong   = errors.New("smpp: replacement does not fit in one short message")
+	ErrOriginClosed     = errors.New("smpp: connection that submitted the message is closed")
+	ErrNoBroadcastArea  = errors.New("smpp: broadcast has no area")
+	ErrNotReceipt       = errors.New("smpp: not a delivery receipt")
+	ErrNotTracked       = errors.New("smpp: message not tracked")
+	ErrReceiptsUnread   = errors.New("smpp: delivery receipts are not read")
 )
+
+// TimeoutError reports a request that got no response within the
+// ResponseTimeout of its connection. It matches ErrResponseTimeout. Header is
+// a copy of the request header as sent: the request itself may already be
+// resubmitted under another sequence number.
+type TimeoutError struct {
+	ConnectionID string
+	Header       pdu.Header
+	Duration     time.Duration
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf(
+		"smpp: %s (sequence %d) on %s got no response within %s",
+		e.Header.CommandID, e.Header.Sequence, e.ConnectionID, e.Duration,
+	)
+}
+
+func (e *TimeoutError) Timeout() bool {
+	return true
+}
+
+func (e *TimeoutError) Is(target error) bool {
+	return target == ErrResponseTimeout
+}
+
+// StatusError is returned when a request is answered with a non-zero command
+// status or a generic_nack. It matches, through errors.Is, the status both as
+// a pdu.CommandStatus and as the constant.CMDStatus of the same code, so
+// callers can branch on e.g. constant.ESME_RTHROTTLED.
+type StatusError struct {
+	Status   pdu.CommandStatus
+	Response interface{}
+}
+
+func (e *StatusError) Error() string {
+	if e.IsNack() {
+		return fmt.Sprintf("smpp: generic_nack %s: %s", e.Status, e.Description())
+	}
+	return fmt.Sprintf("smpp: %s: %s", e.Status, e.Description())
+}
+
+// Description returns the meaning of the status as defined by the
+// specification.
+func (e *StatusError) Description() string {
+	return constant.CMDStatus(e.Status).Error()
+}
+
+// IsNack reports whether the request was rejected with a generic_nack.
+func (e *StatusError) IsNack() bool {
+	_, ok := e.Response.(*pdu.GenericNACK)
+	return ok
+}
+
+func (e *StatusError) Is(target error) bool {
+	switch t := target.(type) {
+	case constant.CMDStatus:
+		return uint32(t) == uint32(e.Status)
+	case *StatusError:
+		return t.Status == e.Status
+	}
+	return false
+}
+
+func (e *StatusError) Unwrap() error {
+	return e.Status
+}
+
+// statusError returns the error carried by a response, if any.
+func statusError(resp interface{}) error {
+	status := pdu.ReadCommandStatus(resp)
+	if _, ok := resp.(*pdu.GenericNACK); ok && status == 0 {
+		status = pdu.ErrUnknownError
+	}
+	if status == 0 {
+		return nil
+	}
+	return &StatusError{Status: status, Response: resp}
+}
@@ -0,0 +1,47 @@
+package smpp_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sujit-baniya/smpp"
+	"github.com/sujit-baniya/smpp/pdu"
+	"github.com/sujit-baniya/smpp/smpptest"
+)
+
+func TestFaults(t *testing.T) {
+	tests := []struct {
+		name  string
+		fault smpptest.Fault
+		err   error
+	}{
+		{"discarded", smpptest.Discarded, smpp.ErrResponseTimeout},
+		{"dropped", smpptest.Dropped, smpp.ErrConnectionClosed},
+		{"unbound", smpptest.Unbound, smpp.ErrConnectionClosed},
+		{"throttled", smpptest.Throttled, pdu.ErrThrottled},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := newServer(t)
+			manager := newManager(t, server, smpp.Setting{ResponseTimeout: 100 * time.Millisecond})
+			server.Inject(test.fault)
+			_, err := manager.Send(smpp.SendRequest{From: "INFO", To: "491", Message: "faulty"})
+			if !errors.Is(err, test.err) {
+				t.Fatalf("got %v, want %v", err, test.err)
+			}
+		})
+	}
+}
+
+func TestMalformedResponse(t *testing.T) {
+	server := newServer(t)
+	manager := newManager(t, server, smpp.Setting{})
+	server.Inject(smpptest.Malformed)
+	if _, err := manager.Send(smpp.SendRequest{From: "INFO", To: "491", Message: "malformed"}); err == nil {
+		t.Fatal("malformed response accepted")
+	}
+	if _, err := manager.Send(smpp.SendRequest{From: "INFO", To: "491", Message: "next"}); err != nil {
+		t.Fatalf("connection unusable after a malformed response: %v", err)
+	}
+}
@@ -23,7 +23,7 @@ type ManagerInterface interface {
 	GetConnection(conIds ...string) ConnectionInterface
 	SetupConnection() error
 	Rebind() error
-	Send(payload interface{}, connectionID ...string) (interface{}, error)
+	Send(req SendRequest, connectionID ...string) (*SendResult, error)
 	Close(connectionID ...string) error
 }
 
@@ -34,21 +34,41 @@ type Auth struct {
 }
 
 type Setting struct {
-	Name             string
-	Slug             string
-	URL              string
-	Auth             Auth
-	SmppVersion      pdu.InterfaceVersion
-	ReadTimeout      time.Duration
-	WriteTimeout     time.Duration
-	EnquiryInterval  time.Duration
-	EnquiryTimeout   time.Duration
-	MaxConnection    int
-	Balancer         balancer.Balancer
-	Throttle         int
-	UseAllConnection bool
-	HandlePDU        func(conn *Conn)
-	AutoRebind       bool
+	Name                 string
+	Slug                 string
+	URL                  string
+	Auth                 Auth
+	SmppVersion          pdu.InterfaceVersion
+	ReadTimeout          time.Duration
+	WriteTimeout         time.Duration
+	EnquiryInterval      time.Duration
+	EnquiryTimeout       time.Duration
+	MaxConnection        int // sessions of any bind mode, receivers and outbinds included
+	Balancer             balancer.Balancer
+	Throttle             int
+	AdaptiveThrottle     *AdaptiveThrottle
+	WindowSize           int
+	ResponseTimeout      time.Duration
+	OnTimeout            func(err *TimeoutError)
+	OnDeliveryReceipt    func(receipt *DeliveryReceipt) error // called in order, off the read loop of each connection
+	Store                Store
+	Concatenation        Concatenation
+	StickySegments       bool
+	PinOperations        bool
+	Retry                RetryPolicy
+	Queue                Queue
+	QueueWorkers         int
+	QueueMaxRequeues     int
+	UseAllConnection     bool
+	HandlePDU            func(conn *Conn)
+	BindMode             BindMode
+	Transmitters         int
+	Receivers            int
+	AutoRebind           bool
+	OutbindAddr          string
+	OutbindAuthenticator func(systemID, password string) error
+	RebindBackoff        Backoff
+	OnSessionEvent       func(event SessionEvent)
 }
 
 type Manager struct {
@@ -56,40 +76,67 @@ type Manager struct {
 	Slug        string
 	ID          string
 	ctx         context.Context
+	cancel      context.CancelFunc
 	setting     Setting
 	connections map[string]*Conn
 	Balancer    balancer.Balancer
 	connIDs     []string
+	receiverIDs []string
+	listeners   []net.Listener
+	handling    bool
+	draining    context.Context
+	origins     origins
+	tracking    sync.Mutex
+	awaiting    awaiting
 	mu          sync.RWMutex
 }
 
 type HandlePDU func(conn *Conn)
 
-type Message struct {
-	From    string
-	To      string
-	Message string
-}
-
 func NewManager(setting Setting) (*Manager, error) {
 	if setting.MaxConnection == 0 {
 		setting.MaxConnection = 1
 	}
+	if pools := setting.Transmitters + setting.Receivers; setting.MaxConnection < pools {
+		setting.MaxConnection = pools
+	}
 	manager := &Manager{
 		Name:        setting.Name,
 		Slug:        setting.Slug,
 		ID:          xid.New().String(),
-		ctx:         context.Background(),
 		setting:     setting,
 		connections: make(map[string]*Conn),
+		Balancer:    setting.Balancer,
 	}
-	if setting.Balancer == nil {
+	manager.ctx, manager.cancel = context.WithCancel(context.Background())
+	if manager.Balancer == nil {
 		manager.Balancer = &balancer.RoundRobin{}
 	}
 	return manager, nil
 }
 
+// Start binds the configured connections. When Setting.Transmitters or
+// Setting.Receivers is set, the manager keeps split pools of transmitter and
+// receiver binds instead: Send balances over the transmitters only, while
+// HandlePDU serves both.
 func (m *Manager) Start() error {
+	m.mu.RLock()
+	ctx := m.ctx
+	m.mu.RUnlock()
+	m.drain(ctx)
+	if m.setting.Transmitters > 0 || m.setting.Receivers > 0 {
+		for _, pool := range []struct {
+			mode BindMode
+			size int
+		}{{BindTransmitter, m.setting.Transmitters}, {BindReceiver, m.setting.Receivers}} {
+			for i := m.poolSize(pool.mode); i < pool.size; i++ {
+				if err := m.SetupConnectionMode(pool.mode); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
 	if m.setting.UseAllConnection {
 		for i := 0; i < m.setting.MaxConnection; i++ {
 			err := m.SetupConnection()
@@ -99,7 +146,7 @@ func (m *Manager) Start() error {
 		}
 		return nil
 	}
-	if len(m.connIDs) == 0 {
+	if m.poolSize(m.setting.BindMode) == 0 {
 		err := m.SetupConnection()
 		if err != nil {
 			return err
@@ -116,10 +163,11 @@ func (m *Manager) AddConnection(noOfConnection ...int) error {
 	if con > m.setting.MaxConnection {
 		return errors.New("Can't create more than allowed no of connections.")
 	}
-	if (len(m.connIDs) + con) > m.setting.MaxConnection {
+	active := m.connectionCount()
+	if (active + con) > m.setting.MaxConnection {
 		return errors.New("There are active sessions. Can't create more than allowed no of sessions.")
 	}
-	connLeft := m.setting.MaxConnection - len(m.connIDs)
+	connLeft := m.setting.MaxConnection - active
 	n := 0
 	if connLeft >= con {
 		n = con
@@ -136,25 +184,10 @@ func (m *Manager) AddConnection(noOfConnection ...int) error {
 }
 
 func (m *Manager) RemoveConnection(conID ...string) error {
-	if len(conID) > 0 {
-		for _, id := range conID {
-			if con, ok := m.connections[id]; ok {
-				err := con.Close()
-				if err != nil {
-					return err
-				}
-				m.connIDs = remove(m.connIDs, id)
-				delete(m.connections, id)
-			}
-		}
-	} else {
-		for id, con := range m.connections {
-			err := con.Close()
-			if err != nil {
-				return err
-			}
-			m.connIDs = remove(m.connIDs, id)
-			delete(m.connections, id)
+	for _, con := range m.detach(conID...) {
+		err := con.Close()
+		if err != nil {
+			return err
 		}
 	}
 	return nil
@@ -162,47 +195,147 @@ func (m *Manager) RemoveConnection(conID ...string) error {
 
 func (m *Manager) Rebind() error {
 	m.Close()
-	m.connections = make(map[string]*Conn)
-	m.connIDs = []string{}
 	m.Start()
 	return m.HandlePDU()
 }
 
 func (m *Manager) SetupConnection() error {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-	parent, err := net.Dial("tcp", m.setting.URL)
+	return m.SetupConnectionMode(m.setting.BindMode)
+}
+
+func (m *Manager) SetupConnectionMode(mode BindMode) error {
+	conn, err := m.bind(mode)
 	if err != nil {
 		return err
 	}
+	m.mu.RLock()
+	ctx := m.ctx
+	m.mu.RUnlock()
+	m.register(ctx, conn)
+	return nil
+}
+
+// bind dials the SMSC and binds a new connection without registering it.
+func (m *Manager) bind(mode BindMode) (*Conn, error) {
+	parent, err := net.Dial("tcp", m.setting.URL)
+	if err != nil {
+		return nil, err
+	}
+	conn := m.newConn(parent, mode)
+	go conn.Watch()
+	_, err = conn.Bind(context.Background(), mode.packet(m.setting.Auth, m.setting.SmppVersion))
+	if err != nil {
+		conn.terminate()
+		return nil, err
+	}
+	return conn, nil
+}
+
+func (m *Manager) newConn(parent net.Conn, mode BindMode) *Conn {
 	conn := NewConn(context.Background(), parent, m.setting.Throttle)
 	conn.WriteTimeout = m.setting.WriteTimeout
 	conn.ReadTimeout = m.setting.ReadTimeout
-	go conn.Watch()
-	resp, err := conn.Bind(context.Background(), &pdu.BindTransceiver{
-		SystemID:   m.setting.Auth.SystemID,
-		Password:   m.setting.Auth.Password,
-		SystemType: m.setting.Auth.SystemType,
-		Version:    m.setting.SmppVersion,
-	})
-	if err != nil {
-		return err
+	conn.Mode = mode
+	conn.OnTimeout = m.setting.OnTimeout
+	conn.OnDeliveryReceipt = m.onDeliveryReceipt()
+	conn.observeReceipt = m.awaiting.observe
+	if m.setting.ResponseTimeout > 0 {
+		conn.ResponseTimeout = m.setting.ResponseTimeout
+	}
+	if m.setting.WindowSize > 0 {
+		conn.Window = NewWindow(m.setting.WindowSize)
+	}
+	if m.setting.AdaptiveThrottle != nil && conn.RateLimiter != nil {
+		conn.Adaptive = NewAdaptiveLimiter(conn.RateLimiter, *m.setting.AdaptiveThrottle)
+		conn.Adaptive.id = conn.ID
+	}
+	return conn
+}
+
+// register makes a bound connection available to the balancer, starts its
+// keep-alive and, once HandlePDU has been called, its PDU handler.
+func (m *Manager) register(ctx context.Context, conn *Conn) {
+	m.admit(ctx, conn, 0)
+}
+
+// admit registers conn unless limit is positive and that many connections are
+// registered already, and reports whether it did.
+func (m *Manager) admit(ctx context.Context, conn *Conn, limit int) bool {
+	m.mu.Lock()
+	if limit > 0 && len(m.connections) >= limit {
+		m.mu.Unlock()
+		return false
 	}
-	r := resp.(*pdu.BindTransceiverResp)
-	if r.Header.CommandStatus == 0 {
-		// start keep-alive
+	// start keep-alive
+	if m.setting.EnquiryInterval > 0 {
 		go conn.EnquireLink(m.setting.EnquiryInterval, m.setting.EnquiryTimeout)
+	}
+	if conn.Mode.CanTransmit() {
 		m.connIDs = append(m.connIDs, conn.ID)
-		m.connections[conn.ID] = conn
+	} else {
+		m.receiverIDs = append(m.receiverIDs, conn.ID)
 	}
-	return nil
+	m.connections[conn.ID] = conn
+	handling := m.handling
+	m.mu.Unlock()
+	m.emit(SessionEvent{ConnectionID: conn.ID, State: SessionBound})
+	if handling && m.setting.HandlePDU != nil {
+		go m.setting.HandlePDU(conn)
+	}
+	go m.watch(ctx, conn)
+	return true
+}
+
+// detach unregisters the given connections, or all of them, and returns them.
+func (m *Manager) detach(conID ...string) (conns []*Conn) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(conID) == 0 {
+		for id := range m.connections {
+			conID = append(conID, id)
+		}
+	}
+	for _, id := range conID {
+		if con, ok := m.connections[id]; ok {
+			conns = append(conns, con)
+			m.unregister(id)
+		}
+	}
+	return
+}
+
+func (m *Manager) unregister(id string) {
+	m.connIDs = remove(m.connIDs, id)
+	m.receiverIDs = remove(m.receiverIDs, id)
+	delete(m.connections, id)
+}
+
+// connectionCount returns the number of registered sessions, whatever their
+// bind mode.
+func (m *Manager) connectionCount() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.connections)
+}
+
+func (m *Manager) poolSize(mode BindMode) (n int) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, conn := range m.connections {
+		if conn.Mode == mode {
+			n++
+		}
+	}
+	return
 }
 
 func (m *Manager) GetConnection(conIds ...string) ConnectionInterface {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
 	var pickedID string
 	if len(conIds) > 0 { // pick among custom
 		pickedID, _ = m.Balancer.Pick(conIds)
-		if con, ok := m.connections[pickedID]; ok {
+		if con, ok := m.connections[pickedID]; ok && con.Mode.CanTransmit() {
 			return con
 		}
 	}
@@ -213,48 +346,41 @@ func (m *Manager) GetConnection(conIds ...string) ConnectionInterface {
 	return con
 }
 
-func (m *Manager) Send(payload interface{}, connectionId ...string) (interface{}, error) {
-	sms := payload.(Message)
-	shortMessages, err := m.Compose(sms.Message)
-	if err != nil {
-		panic(err)
-	}
-	responses := make(map[*pdu.SubmitSM]*pdu.SubmitSMResp)
-	responseChan := make(chan map[*pdu.SubmitSM]*pdu.SubmitSMResp)
-	wg := &sync.WaitGroup{}
-	for _, shortMessage := range shortMessages {
-		wg.Add(1)
-		go m.SendShortMessage(sms.From, sms.To, shortMessage, wg, responseChan, connectionId...)
-	}
-	go func() {
-		wg.Wait()
-		close(responseChan)
-	}()
-	for response := range responseChan {
-		for submitSM, submitSMResp := range response {
-			responses[submitSM] = submitSMResp
+// Send submits every segment of the request and reports the outcome of each
+// one; the returned error is that of the first failed segment. An SMSC
+// rejection is a *StatusError. With a Setting.Queue, a request not bound to
+// specific connections is enqueued instead and only SendResult.QueueID is set.
+func (m *Manager) Send(req SendRequest, connectionId ...string) (*SendResult, error) {
+	return m.SendContext(context.Background(), req, connectionId...)
+}
+
+// SendContext is like Send but gives up waiting on the rate limiter, the
+// window, retries and responses once ctx ends. Enqueueing is not affected.
+func (m *Manager) SendContext(ctx context.Context, req SendRequest, connectionId ...string) (*SendResult, error) {
+	if m.setting.Queue != nil && len(connectionId) == 0 {
+		id, err := m.Enqueue(req)
+		if err != nil {
+			return nil, err
 		}
+		return &SendResult{QueueID: id}, nil
 	}
-	return responses, nil
+	return m.send(ctx, "", req, connectionId...)
 }
 
 func (m *Manager) SendShortMessage(from string, to string, shortMessage pdu.ShortMessage, wg *sync.WaitGroup, responseChan chan<- map[*pdu.SubmitSM]*pdu.SubmitSMResp, connectionId ...string) error {
+	return m.SendShortMessageContext(context.Background(), from, to, shortMessage, wg, responseChan, connectionId...)
+}
 
+// SendShortMessageContext is like SendShortMessage but stops waiting once ctx
+// ends.
+func (m *Manager) SendShortMessageContext(ctx context.Context, from string, to string, shortMessage pdu.ShortMessage, wg *sync.WaitGroup, responseChan chan<- map[*pdu.SubmitSM]*pdu.SubmitSMResp, connectionId ...string) error {
 	defer wg.Done()
-	conn := m.GetConnection(connectionId...).(*Conn)
-	packet := m.Prepare(from, to, shortMessage)
-	err := conn.Throttle()
-	if err != nil {
-		return err
-	}
-	resp, err := conn.Submit(m.ctx, packet)
-	if err != nil {
-		return err
+	segment, resp := m.sendSegment(ctx, m.Prepare(from, to, shortMessage), connectionId...)
+	if segment.Err != nil {
+		m.deadLetter("", []SegmentResult{segment})
+		return segment.Err
 	}
-	mp := map[*pdu.SubmitSM]*pdu.SubmitSMResp{
-		packet: resp.(*pdu.SubmitSMResp),
-	}
-	responseChan <- mp
+	responseChan <- map[*pdu.SubmitSM]*pdu.SubmitSMResp{segment.Packet: resp}
 	return nil
 }
 
@@ -262,7 +388,7 @@ func (m *Manager) Prepare(from string, to string, shortMessage pdu.ShortMessage)
 	return &pdu.SubmitSM{
 		SourceAddr: parseSrcPhone(from),
 		DestAddr:   parseDestPhone(to),
-		ESMClass:   pdu.ESMClass{UDHIndicator: true},
+		ESMClass:   pdu.ESMClass{UDHIndicator: len(shortMessage.UDHeader) > 0},
 		RegisteredDelivery: pdu.RegisteredDelivery{
 			MCDeliveryReceipt:           1,
 			SMEOriginatedAcknowledgment: 1,
@@ -273,28 +399,44 @@ func (m *Manager) Prepare(from string, to string, shortMessage pdu.ShortMessage)
 	}
 }
 
+// Close unbinds the given connections. Without arguments it closes every
+// connection and outbind listener and stops pending reconnects; the manager
+// can be started again.
 func (m *Manager) Close(connectionId ...string) error {
-	if len(connectionId) > 0 {
-		if con, ok := m.connections[connectionId[0]]; ok {
-			err := con.Close()
-			if err != nil {
-				return err
-			}
+	if len(connectionId) == 0 {
+		m.mu.Lock()
+		m.cancel()
+		m.ctx, m.cancel = context.WithCancel(context.Background())
+		for _, listener := range m.listeners {
+			_ = listener.Close()
 		}
-	} else {
-		for _, conn := range m.connections {
-			err := conn.Close()
-			if err != nil {
-				return err
-			}
+		m.listeners = nil
+		m.mu.Unlock()
+	}
+	var err error
+	for _, conn := range m.detach(connectionId...) {
+		if closeErr := conn.Close(); err == nil {
+			err = closeErr
 		}
 	}
-
-	return nil
+	return err
 }
 
+// HandlePDU attaches Setting.HandlePDU to every connection. Connections bound
+// afterwards, including rebinds, get the handler attached on registration.
 func (m *Manager) HandlePDU() error {
+	m.mu.Lock()
+	if m.handling || m.setting.HandlePDU == nil {
+		m.mu.Unlock()
+		return nil
+	}
+	m.handling = true
+	var conns []*Conn
 	for _, conn := range m.connections {
+		conns = append(conns, conn)
+	}
+	m.mu.Unlock()
+	for _, conn := range conns {
 		go m.setting.HandlePDU(conn)
 	}
 	return nil
@@ -306,13 +448,17 @@ func (m *Manager) Compose(msg string) ([]pdu.ShortMessage, error) {
 
 func Compose(msg string, smppVersion pdu.InterfaceVersion) ([]pdu.ShortMessage, error) {
 	reference := uint16(rand.Intn(0xFFFF))
-	var dataCoding coding.DataCoding
-	if smppVersion == pdu.SMPPVersion50 {
-		dataCoding = coding.BestCoding(msg)
-	} else {
-		dataCoding = coding.BestSafeCoding(msg)
+	return pdu.ComposeMultipartShortMessage(msg, bestCoding(msg, smppVersion), reference)
+}
+
+// ComposeSegments splits msg according to Setting.Concatenation, with the
+// data coding picked for the text unless dataCoding is given.
+func (m *Manager) ComposeSegments(msg string, dataCoding ...coding.DataCoding) ([]Segment, error) {
+	picked := bestCoding(msg, m.setting.SmppVersion)
+	if len(dataCoding) > 0 {
+		picked = dataCoding[0]
 	}
-	return pdu.ComposeMultipartShortMessage(msg, dataCoding, reference)
+	return ComposeSegments(msg, picked, m.setting.Concatenation, uint16(rand.Intn(0xFFFF)))
 }
 
 func parseSrcPhone(phone string) pdu.Address {
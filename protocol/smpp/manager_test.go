@@ -0,0 +1,59 @@
+package smpp_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sujit-baniya/smpp"
+	"github.com/sujit-baniya/smpp/smpptest"
+)
+
+func newServer(t *testing.T) *smpptest.Server {
+	t.Helper()
+	server := smpptest.NewServer()
+	t.Cleanup(server.Close)
+	return server
+}
+
+// newManager starts a manager bound to server. Unless the setting reads them
+// otherwise, receipts are acknowledged and dropped.
+func newManager(t *testing.T, server *smpptest.Server, setting smpp.Setting) *smpp.Manager {
+	t.Helper()
+	setting.URL = server.Addr
+	setting.Auth = smpp.Auth{SystemID: "test", Password: "secret"}
+	if setting.OnDeliveryReceipt == nil && setting.HandlePDU == nil && setting.Store == nil {
+		setting.OnDeliveryReceipt = func(*smpp.DeliveryReceipt) error { return nil }
+	}
+	manager, err := smpp.NewManager(setting)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = manager.Start(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = manager.Close() })
+	return manager
+}
+
+// eventually polls condition until it holds or a few seconds pass.
+func eventually(t *testing.T, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestMaxConnectionCountsReceivers(t *testing.T) {
+	server := newServer(t)
+	manager := newManager(t, server, smpp.Setting{Transmitters: 1, Receivers: 1})
+	if err := manager.AddConnection(); err == nil {
+		t.Fatal("opened a third session with room for two")
+	}
+	if got := len(server.SMSC.Sessions()); got != 2 {
+		t.Fatalf("%d sessions bound, want 2", got)
+	}
+}
@@ -0,0 +1,189 @@
+package smpp
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/sujit-baniya/smpp/coding"
+	"github.com/sujit-baniya/smpp/pdu"
+)
+
+// MaxMultiDestinations is the number of destinations a submit_multi carries.
+const MaxMultiDestinations = 0xFF
+
+// MultiRequest is a text message for several recipients, given as addresses
+// and as distribution list names defined on the SMSC.
+type MultiRequest struct {
+	From              string
+	To                []string
+	DistributionLists []string
+	Message           string
+	Options           SendOptions
+}
+
+// MultiResult reports the outcome of a MultiRequest. The destinations are
+// split into batches of at most MaxMultiDestinations and every segment of the
+// message is submitted once per batch.
+type MultiResult struct {
+	DataCoding coding.DataCoding
+	Batches    []BatchResult
+}
+
+// BatchResult is the outcome of one submit_multi.
+type BatchResult struct {
+	Segment      int
+	Packet       *pdu.SubmitMulti
+	MessageID    string
+	Status       pdu.CommandStatus
+	Err          error
+	ConnectionID string
+	Attempts     []Attempt
+	Failed       []RecipientFailure
+}
+
+// RecipientFailure is a destination the message could not be submitted to.
+type RecipientFailure struct {
+	Recipient string
+	Segment   int
+	Status    pdu.CommandStatus
+	Err       error
+}
+
+// Err returns the error of the first failed batch.
+func (r *MultiResult) Err() error {
+	for _, batch := range r.Batches {
+		if batch.Err != nil {
+			return batch.Err
+		}
+	}
+	return nil
+}
+
+// Failed returns every recipient that was refused, either individually in
+// the unsuccessful_sme list of a response or with its whole batch.
+func (r *MultiResult) Failed() (failed []RecipientFailure) {
+	for _, batch := range r.Batches {
+		failed = append(failed, batch.Failed...)
+	}
+	return
+}
+
+// SendMulti submits req with submit_multi.
+func (m *Manager) SendMulti(req MultiRequest, connectionId ...string) (*MultiResult, error) {
+	return m.SendMultiContext(context.Background(), req, connectionId...)
+}
+
+// SendMultiContext is like SendMulti but stops waiting once ctx ends. Batches
+// are submitted concurrently, the segments of a batch in order.
+func (m *Manager) SendMultiContext(ctx context.Context, req MultiRequest, connectionId ...string) (*MultiResult, error) {
+	batches := destinationBatches(req.To, req.DistributionLists)
+	if len(batches) == 0 {
+		return nil, ErrNoRecipient
+	}
+	var segments []Segment
+	var err error
+	if req.Options.DataCoding != nil {
+		segments, err = m.ComposeSegments(req.Message, *req.Options.DataCoding)
+	} else {
+		segments, err = m.ComposeSegments(req.Message)
+	}
+	if err != nil {
+		return nil, err
+	}
+	result := &MultiResult{}
+	if len(segments) > 0 {
+		result.DataCoding = segments[0].Message.DataCoding
+	}
+	result.Batches = make([]BatchResult, len(batches)*len(segments))
+	var wg sync.WaitGroup
+	for b, batch := range batches {
+		wg.Add(1)
+		go func(b int, batch pdu.DestinationAddresses) {
+			defer wg.Done()
+			for i, segment := range segments {
+				packet := m.Prepare(req.From, "", segment.Message)
+				packet.Tags = segment.Tags
+				req.Options.apply(packet)
+				result.Batches[b*len(segments)+i] = m.submitMulti(ctx, i, multiPacket(packet, batch), connectionId...)
+			}
+		}(b, batch)
+	}
+	wg.Wait()
+	return result, result.Err()
+}
+
+func (m *Manager) submitMulti(ctx context.Context, segment int, packet *pdu.SubmitMulti, connectionId ...string) BatchResult {
+	resp, attempts, err := m.submit(ctx, packet, connectionId...)
+	batch := BatchResult{Segment: segment, Packet: packet, Err: err, Attempts: attempts}
+	if n := len(attempts); n > 0 {
+		batch.ConnectionID = attempts[n-1].ConnectionID
+	}
+	var status *StatusError
+	if errors.As(err, &status) {
+		batch.Status = status.Status
+	}
+	if r, ok := resp.(*pdu.SubmitMultiResp); ok && err == nil {
+		batch.MessageID = r.MessageID
+		m.remember(batch.MessageID, batch.ConnectionID)
+		for _, record := range r.UnsuccessfulSMEs {
+			batch.Failed = append(batch.Failed, RecipientFailure{
+				Recipient: record.DestAddr.No,
+				Segment:   segment,
+				Status:    record.ErrorStatusCode,
+				Err:       &StatusError{Status: record.ErrorStatusCode, Response: r},
+			})
+		}
+		return batch
+	} else if err == nil {
+		batch.Err = statusError(resp)
+	}
+	for _, address := range packet.DestAddrList.Addresses {
+		batch.Failed = append(batch.Failed, RecipientFailure{Recipient: address.No, Segment: segment, Status: batch.Status, Err: batch.Err})
+	}
+	for _, list := range packet.DestAddrList.DistributionList {
+		batch.Failed = append(batch.Failed, RecipientFailure{Recipient: list, Segment: segment, Status: batch.Status, Err: batch.Err})
+	}
+	return batch
+}
+
+// destinationBatches splits the recipients into lists of at most
+// MaxMultiDestinations, addresses first.
+func destinationBatches(to, lists []string) (batches []pdu.DestinationAddresses) {
+	var batch pdu.DestinationAddresses
+	flush := func() {
+		if len(batch.Addresses)+len(batch.DistributionList) == MaxMultiDestinations {
+			batches = append(batches, batch)
+			batch = pdu.DestinationAddresses{}
+		}
+	}
+	for _, number := range to {
+		batch.Addresses = append(batch.Addresses, parseDestPhone(number))
+		flush()
+	}
+	for _, list := range lists {
+		batch.DistributionList = append(batch.DistributionList, list)
+		flush()
+	}
+	if len(batch.Addresses)+len(batch.DistributionList) > 0 {
+		batches = append(batches, batch)
+	}
+	return
+}
+
+func multiPacket(sm *pdu.SubmitSM, destinations pdu.DestinationAddresses) *pdu.SubmitMulti {
+	return &pdu.SubmitMulti{
+		ServiceType:          sm.ServiceType,
+		SourceAddr:           sm.SourceAddr,
+		DestAddrList:         destinations,
+		ESMClass:             sm.ESMClass,
+		ProtocolID:           sm.ProtocolID,
+		PriorityFlag:         sm.PriorityFlag,
+		ScheduleDeliveryTime: sm.ScheduleDeliveryTime,
+		ValidityPeriod:       sm.ValidityPeriod,
+		RegisteredDelivery:   sm.RegisteredDelivery,
+		ReplaceIfPresent:     sm.ReplaceIfPresent,
+		Message:              sm.Message,
+		Tags:                 sm.Tags,
+	}
+}
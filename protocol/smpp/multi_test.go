@@ -0,0 +1,78 @@
+package smpp_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/sujit-baniya/smpp"
+	"github.com/sujit-baniya/smpp/pdu"
+	"github.com/sujit-baniya/smpp/smpptest"
+)
+
+func TestSendMulti(t *testing.T) {
+	server := newServer(t)
+	server.RecipientStatus = func(to string) pdu.CommandStatus {
+		if to == "49299" {
+			return pdu.ErrMessageQueueFull
+		}
+		return 0
+	}
+	manager := newManager(t, server, smpp.Setting{})
+	var to []string
+	for i := 0; i < 300; i++ {
+		to = append(to, fmt.Sprintf("492%02d", i))
+	}
+	result, err := manager.SendMulti(smpp.MultiRequest{From: "INFO", To: to, DistributionLists: []string{"staff"}, Message: "hi"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Batches) != 2 {
+		t.Fatalf("%d batches, want 2", len(result.Batches))
+	}
+	submitted := server.SubmittedMulti()
+	if len(submitted) != 2 {
+		t.Fatalf("%d submit_multi, want 2", len(submitted))
+	}
+	destinations := 0
+	for _, p := range submitted {
+		n := len(p.DestAddrList.Addresses) + len(p.DestAddrList.DistributionList)
+		if n > smpp.MaxMultiDestinations {
+			t.Fatalf("submit_multi to %d destinations", n)
+		}
+		destinations += n
+	}
+	if destinations != 301 {
+		t.Fatalf("%d destinations submitted, want 301", destinations)
+	}
+	failed := result.Failed()
+	if len(failed) != 1 || failed[0].Recipient != "49299" || failed[0].Status != pdu.ErrMessageQueueFull {
+		t.Fatalf("failed recipients %+v", failed)
+	}
+	if !errors.Is(failed[0].Err, pdu.ErrMessageQueueFull) {
+		t.Fatalf("recipient error %v", failed[0].Err)
+	}
+
+	server.Inject(smpptest.QueueFull)
+	result, err = manager.SendMulti(smpp.MultiRequest{From: "INFO", To: []string{"491", "492"}, Message: "hi"})
+	if !errors.Is(err, pdu.ErrMessageQueueFull) {
+		t.Fatalf("refused batch: %v", err)
+	}
+	if failed = result.Failed(); len(failed) != 2 || failed[0].Recipient != "491" || failed[1].Recipient != "492" {
+		t.Fatalf("failed recipients of a refused batch %+v", failed)
+	}
+}
+
+func TestSendNoRecipient(t *testing.T) {
+	server := newServer(t)
+	manager := newManager(t, server, smpp.Setting{})
+	if _, err := manager.Send(smpp.SendRequest{From: "INFO", Message: "hi"}); !errors.Is(err, smpp.ErrNoRecipient) {
+		t.Fatalf("send without a recipient: %v", err)
+	}
+	if _, err := manager.SendMulti(smpp.MultiRequest{From: "INFO", Message: "hi"}); !errors.Is(err, smpp.ErrNoRecipient) {
+		t.Fatalf("send_multi without a recipient: %v", err)
+	}
+	if n := len(server.Submitted()) + len(server.SubmittedMulti()); n != 0 {
+		t.Fatalf("%d packets submitted", n)
+	}
+}
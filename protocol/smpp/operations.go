@@ -0,0 +1,185 @@
+package smpp
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sujit-baniya/smpp/pdu"
+)
+
+// QueryResult is the state of a message reported by query_sm. FinalDate is
+// zero until the message reaches a final state.
+type QueryResult struct {
+	MessageID string
+	State     pdu.MessageState
+	FinalDate time.Time
+	ErrorCode byte
+}
+
+// CancelRequest identifies the messages to cancel: the message with
+// MessageID or, when it is empty, every pending message from From to To with
+// ServiceType.
+type CancelRequest struct {
+	MessageID   string
+	ServiceType string
+	From        string
+	To          string
+}
+
+// ReplaceRequest replaces the text of a pending message. Only the validity,
+// schedule, receipt and data coding options apply.
+type ReplaceRequest struct {
+	MessageID string
+	From      string
+	Message   string
+	Options   SendOptions
+}
+
+// QuerySM asks the SMSC for the state of a message submitted from from.
+func (m *Manager) QuerySM(ctx context.Context, messageID, from string, connectionId ...string) (*QueryResult, error) {
+	ids, err := m.operationConnections(messageID, connectionId)
+	if err != nil {
+		return nil, err
+	}
+	packet := &pdu.QuerySM{MessageID: messageID, SourceAddr: parseSrcPhone(from)}
+	resp, err := m.operate(ctx, packet, ids...)
+	if err != nil {
+		return nil, err
+	}
+	r, ok := resp.(*pdu.QuerySMResp)
+	if !ok {
+		return nil, statusError(resp)
+	}
+	result := &QueryResult{MessageID: r.MessageID, State: r.MessageState, ErrorCode: r.ErrorCode}
+	var final pdu.Time
+	if err = final.From(r.FinalDate); err == nil {
+		result.FinalDate = final.Time
+	}
+	return result, nil
+}
+
+// CancelSM cancels messages not delivered yet.
+func (m *Manager) CancelSM(ctx context.Context, req CancelRequest, connectionId ...string) error {
+	ids, err := m.operationConnections(req.MessageID, connectionId)
+	if err != nil {
+		return err
+	}
+	packet := &pdu.CancelSM{
+		ServiceType: req.ServiceType,
+		MessageID:   req.MessageID,
+		SourceAddr:  parseSrcPhone(req.From),
+		DestAddr:    parseDestPhone(req.To),
+	}
+	_, err = m.operate(ctx, packet, ids...)
+	return err
+}
+
+// ReplaceSM replaces the text, schedule or validity of a pending message.
+func (m *Manager) ReplaceSM(ctx context.Context, req ReplaceRequest, connectionId ...string) error {
+	var segments []Segment
+	var err error
+	if req.Options.DataCoding != nil {
+		segments, err = ComposeSegments(req.Message, *req.Options.DataCoding, ConcatenateUDH, 0)
+	} else {
+		segments, err = ComposeSegments(req.Message, bestCoding(req.Message, m.setting.SmppVersion), ConcatenateUDH, 0)
+	}
+	if err != nil {
+		return err
+	} else if len(segments) > 1 {
+		return ErrReplaceTooLong
+	}
+	ids, err := m.operationConnections(req.MessageID, connectionId)
+	if err != nil {
+		return err
+	}
+	packet := &pdu.ReplaceSM{
+		MessageID:            req.MessageID,
+		SourceAddr:           parseSrcPhone(req.From),
+		ScheduleDeliveryTime: smppTime(req.Options.ScheduleAt, req.Options.ScheduleIn),
+		ValidityPeriod:       smppTime(req.Options.ValidUntil, req.Options.ValidFor),
+		RegisteredDelivery:   req.Options.registeredDelivery(),
+		Message:              segments[0].Message,
+	}
+	_, err = m.operate(ctx, packet, ids...)
+	return err
+}
+
+// operationConnections returns the connections an operation on messageID may
+// use: the given ones or, with Setting.PinOperations, the one that submitted
+// the message if it is known.
+func (m *Manager) operationConnections(messageID string, connectionId []string) ([]string, error) {
+	if len(connectionId) > 0 || !m.setting.PinOperations {
+		return connectionId, nil
+	}
+	id, ok := m.origins.get(messageID)
+	if !ok {
+		return nil, nil
+	}
+	m.mu.RLock()
+	_, bound := m.connections[id]
+	m.mu.RUnlock()
+	if !bound {
+		return nil, ErrOriginClosed
+	}
+	return []string{id}, nil
+}
+
+// pinnedConnection picks a bound transmitter among connectionID, without
+// falling back to the other connections.
+func (m *Manager) pinnedConnection(connectionID []string) *Conn {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var bound []string
+	for _, id := range connectionID {
+		if conn, ok := m.connections[id]; ok && conn.Mode.CanTransmit() {
+			bound = append(bound, id)
+		}
+	}
+	if len(bound) == 0 {
+		return nil
+	}
+	picked, _ := m.Balancer.Pick(bound)
+	return m.connections[picked]
+}
+
+// remember records the connection that submitted messageID.
+func (m *Manager) remember(messageID, connectionID string) {
+	if m.setting.PinOperations && messageID != "" && connectionID != "" {
+		m.origins.put(messageID, connectionID)
+	}
+}
+
+// maxOrigins bounds the number of message IDs whose connection is kept.
+const maxOrigins = 1 << 16
+
+// origins maps message IDs to connection IDs, forgetting the oldest entries
+// beyond maxOrigins.
+type origins struct {
+	ids   map[string]string
+	order []string
+	mu    sync.Mutex
+}
+
+func (o *origins) put(messageID, connectionID string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.ids == nil {
+		o.ids = make(map[string]string)
+	}
+	if _, ok := o.ids[messageID]; !ok {
+		o.order = append(o.order, messageID)
+	}
+	o.ids[messageID] = connectionID
+	for len(o.order) > maxOrigins {
+		delete(o.ids, o.order[0])
+		o.order = o.order[1:]
+	}
+}
+
+func (o *origins) get(messageID string) (string, bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	id, ok := o.ids[messageID]
+	return id, ok
+}
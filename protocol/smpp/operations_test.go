@@ -0,0 +1,91 @@
+package smpp_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/sujit-baniya/smpp"
+	"github.com/sujit-baniya/smpp/coding"
+	"github.com/sujit-baniya/smpp/pdu"
+)
+
+func TestOperations(t *testing.T) {
+	server := newServer(t)
+	server.RequireOrigin = true
+	manager := newManager(t, server, smpp.Setting{Transmitters: 2, PinOperations: true})
+	ctx := context.Background()
+	pending := smpp.SendOptions{Receipt: smpp.ReceiptNone}
+	result, err := manager.Send(smpp.SendRequest{From: "INFO", To: "491", Message: "first", Options: pending})
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, origin := result.Segments[0].MessageID, result.Segments[0].ConnectionID
+
+	// the SMSC answers only on the submitting session, which pinning picks
+	// over the connection the balancer would choose
+	for i := 0; i < 4; i++ {
+		query, err := manager.QuerySM(ctx, id, "INFO")
+		if err != nil {
+			t.Fatalf("query %d: %v", i, err)
+		}
+		if query.MessageID != id || query.State != pdu.MessageStateEnroute || !query.FinalDate.IsZero() {
+			t.Fatalf("query of a pending message %+v", query)
+		}
+	}
+	stranger := newManager(t, server, smpp.Setting{})
+	if _, err = stranger.QuerySM(ctx, id, "INFO"); !errors.Is(err, pdu.ErrInvalidBindStatus) {
+		t.Fatalf("query from another session: %v", err)
+	}
+	if err = manager.ReplaceSM(ctx, smpp.ReplaceRequest{MessageID: id, From: "INFO", Message: "second"}); err != nil {
+		t.Fatal(err)
+	}
+	replaced := server.Replaced()
+	if len(replaced) != 1 || replaced[0].MessageID != id {
+		t.Fatalf("replaced %+v", replaced)
+	}
+	// replace_sm has no data_coding, the SMSC decodes with its default
+	message := replaced[0].Message
+	message.DataCoding = coding.GSM7BitCoding
+	if text, _ := message.Parse(); text != "second" {
+		t.Fatalf("replacement %q", text)
+	}
+	err = manager.ReplaceSM(ctx, smpp.ReplaceRequest{MessageID: id, From: "INFO", Message: strings.Repeat("x", 200)})
+	if !errors.Is(err, smpp.ErrReplaceTooLong) {
+		t.Fatalf("long replacement: %v", err)
+	}
+	if err = manager.CancelSM(ctx, smpp.CancelRequest{MessageID: id, From: "INFO", To: "491"}); err != nil {
+		t.Fatal(err)
+	}
+	query, err := manager.QuerySM(ctx, id, "INFO")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query.State != pdu.MessageStateDeleted || query.FinalDate.IsZero() {
+		t.Fatalf("query of a cancelled message %+v", query)
+	}
+	if err = manager.CancelSM(ctx, smpp.CancelRequest{MessageID: id}); !errors.Is(err, pdu.ErrCancelFailed) {
+		t.Fatalf("second cancel: %v", err)
+	}
+	if err = manager.ReplaceSM(ctx, smpp.ReplaceRequest{MessageID: id, Message: "late"}); !errors.Is(err, pdu.ErrReplaceFailed) {
+		t.Fatalf("replace of a cancelled message: %v", err)
+	}
+	if _, err = manager.QuerySM(ctx, "unknown", "INFO"); !errors.Is(err, pdu.ErrInvalidMessageID) {
+		t.Fatalf("query of an unknown message: %v", err)
+	}
+
+	if result, err = manager.Send(smpp.SendRequest{From: "INFO", To: "492", Message: "third", Options: pending}); err != nil {
+		t.Fatal(err)
+	}
+	id, origin = result.Segments[0].MessageID, result.Segments[0].ConnectionID
+	if err = manager.RemoveConnection(origin); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = manager.QuerySM(ctx, id, "INFO"); !errors.Is(err, smpp.ErrOriginClosed) {
+		t.Fatalf("query after the origin closed: %v", err)
+	}
+	if err = manager.CancelSM(ctx, smpp.CancelRequest{MessageID: id}); !errors.Is(err, smpp.ErrOriginClosed) {
+		t.Fatalf("cancel after the origin closed: %v", err)
+	}
+}
@@ -0,0 +1,74 @@
+package smpp
+
+import (
+	"time"
+
+	"github.com/sujit-baniya/smpp/coding"
+	"github.com/sujit-baniya/smpp/pdu"
+)
+
+// Receipt selects the delivery receipt requested from the SMSC.
+type Receipt byte
+
+const (
+	ReceiptDefault Receipt = iota // final receipt, SME acknowledgement and intermediate notifications
+	ReceiptNone                   // no receipt
+	ReceiptFinal                  // receipt on final delivery outcome, success or failure
+	ReceiptFailure                // receipt on delivery failure only
+	ReceiptSuccess                // receipt on delivery success only
+)
+
+// SendOptions fill the optional fields of the submit_sm PDUs of a message.
+// Absolute times take precedence over relative ones; zero values leave the
+// field to the SMSC default.
+type SendOptions struct {
+	ServiceType      string
+	ProtocolID       byte
+	Priority         byte
+	ValidFor         time.Duration // relative validity period
+	ValidUntil       time.Time     // absolute validity period
+	ScheduleIn       time.Duration // relative scheduled delivery time
+	ScheduleAt       time.Time     // absolute scheduled delivery time
+	Receipt          Receipt
+	Intermediate     bool               // also request intermediate notifications, with a Receipt other than the default
+	DataCoding       *coding.DataCoding // overrides the coding picked for the text
+	ReplaceIfPresent bool
+}
+
+func (o SendOptions) registeredDelivery() pdu.RegisteredDelivery {
+	switch o.Receipt {
+	case ReceiptNone:
+		return pdu.RegisteredDelivery{IntermediateNotification: o.Intermediate}
+	case ReceiptFinal:
+		return pdu.RegisteredDelivery{MCDeliveryReceipt: 1, IntermediateNotification: o.Intermediate}
+	case ReceiptFailure:
+		return pdu.RegisteredDelivery{MCDeliveryReceipt: 2, IntermediateNotification: o.Intermediate}
+	case ReceiptSuccess:
+		return pdu.RegisteredDelivery{MCDeliveryReceipt: 3, IntermediateNotification: o.Intermediate}
+	}
+	return pdu.RegisteredDelivery{
+		MCDeliveryReceipt:           1,
+		SMEOriginatedAcknowledgment: 1,
+		IntermediateNotification:    true,
+		Reserved:                    7,
+	}
+}
+
+// smppTime formats an absolute or relative SMPP time, preferring at.
+func smppTime(at time.Time, in time.Duration) string {
+	if !at.IsZero() {
+		return pdu.Time{Time: at}.String()
+	}
+	return pdu.Duration{Duration: in}.String()
+}
+
+// apply sets the options on packet.
+func (o SendOptions) apply(packet *pdu.SubmitSM) {
+	packet.ServiceType = o.ServiceType
+	packet.ProtocolID = o.ProtocolID
+	packet.PriorityFlag = o.Priority
+	packet.ValidityPeriod = smppTime(o.ValidUntil, o.ValidFor)
+	packet.ScheduleDeliveryTime = smppTime(o.ScheduleAt, o.ScheduleIn)
+	packet.RegisteredDelivery = o.registeredDelivery()
+	packet.ReplaceIfPresent = o.ReplaceIfPresent
+}
@@ -0,0 +1,74 @@
+package smpp_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sujit-baniya/smpp"
+	"github.com/sujit-baniya/smpp/coding"
+	"github.com/sujit-baniya/smpp/pdu"
+)
+
+func TestSendOptions(t *testing.T) {
+	server := newServer(t)
+	manager := newManager(t, server, smpp.Setting{})
+	ucs2 := coding.UCS2Coding
+	options := []smpp.SendOptions{{
+		ServiceType:      "CMT",
+		ProtocolID:       0x41,
+		Priority:         2,
+		ValidFor:         2*time.Hour + 30*time.Minute,
+		ScheduleAt:       time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC),
+		ScheduleIn:       time.Hour,
+		Receipt:          smpp.ReceiptFailure,
+		Intermediate:     true,
+		DataCoding:       &ucs2,
+		ReplaceIfPresent: true,
+	}, {
+		ValidUntil: time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC),
+		ValidFor:   time.Hour,
+		Receipt:    smpp.ReceiptNone,
+	}, {}}
+	for _, option := range options {
+		if _, err := manager.Send(smpp.SendRequest{From: "INFO", To: "491", Message: "options", Options: option}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	submitted := server.Submitted()
+	if len(submitted) != 3 {
+		t.Fatalf("%d submit_sm, want 3", len(submitted))
+	}
+
+	p := submitted[0]
+	if p.ServiceType != "CMT" || p.ProtocolID != 0x41 || p.PriorityFlag != 2 || !p.ReplaceIfPresent {
+		t.Fatalf("fields of %+v", p)
+	}
+	if p.ValidityPeriod != "000000023000000R" || p.ScheduleDeliveryTime != "300102030405000+" {
+		t.Fatalf("validity %q, schedule %q", p.ValidityPeriod, p.ScheduleDeliveryTime)
+	}
+	if p.RegisteredDelivery != (pdu.RegisteredDelivery{MCDeliveryReceipt: 2, IntermediateNotification: true}) {
+		t.Fatalf("registered delivery %+v", p.RegisteredDelivery)
+	}
+	if p.Message.DataCoding != coding.UCS2Coding {
+		t.Fatalf("data coding %v, want UCS-2", p.Message.DataCoding)
+	}
+	if text, _ := p.Message.Parse(); text != "options" {
+		t.Fatalf("text %q", text)
+	}
+
+	p = submitted[1]
+	if p.ValidityPeriod != "300102030405000+" || p.ScheduleDeliveryTime != "" {
+		t.Fatalf("validity %q, schedule %q", p.ValidityPeriod, p.ScheduleDeliveryTime)
+	}
+	if p.RegisteredDelivery != (pdu.RegisteredDelivery{}) {
+		t.Fatalf("registered delivery %+v", p.RegisteredDelivery)
+	}
+
+	p = submitted[2]
+	if p.ServiceType != "" || p.ValidityPeriod != "" || p.Message.DataCoding != coding.GSM7BitCoding {
+		t.Fatalf("defaults %+v", p)
+	}
+	if want := (pdu.RegisteredDelivery{MCDeliveryReceipt: 1, SMEOriginatedAcknowledgment: 1, IntermediateNotification: true, Reserved: 7}); p.RegisteredDelivery != want {
+		t.Fatalf("default registered delivery %+v", p.RegisteredDelivery)
+	}
+}
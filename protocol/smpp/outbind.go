@@ -0,0 +1,92 @@
+package smpp
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+
+	"github.com/sujit-baniya/smpp/pdu"
+)
+
+// ListenOutbind listens on Setting.OutbindAddr for SMSCs initiating sessions
+// with outbind and serves them with ServeOutbind.
+func (m *Manager) ListenOutbind() error {
+	listener, err := net.Listen("tcp", m.setting.OutbindAddr)
+	if err != nil {
+		return err
+	}
+	return m.ServeOutbind(listener)
+}
+
+// ServeOutbind accepts SMSC connections on listener. Each connection that
+// opens with an outbind is answered with a bind_receiver using Setting.Auth
+// and, once bound, managed like any other receiver connection. Connections
+// beyond Setting.MaxConnection are refused with ErrTooManySessions. The
+// listener is closed by Close.
+func (m *Manager) ServeOutbind(listener net.Listener) error {
+	m.mu.Lock()
+	ctx := m.ctx
+	m.listeners = append(m.listeners, listener)
+	m.mu.Unlock()
+	for {
+		parent, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return ErrServerClosed
+			}
+			var ne net.Error
+			if errors.As(err, &ne) && ne.Timeout() {
+				continue
+			}
+			return err
+		}
+		go func() {
+			if m.connectionCount() >= m.setting.MaxConnection {
+				_ = parent.Close()
+				m.emit(SessionEvent{State: SessionBindFailed, Err: ErrTooManySessions})
+				return
+			}
+			conn, err := m.outbind(parent)
+			if err == nil && !m.admit(ctx, conn, m.setting.MaxConnection) {
+				// another outbind took the last session meanwhile
+				_ = conn.Close()
+				err = ErrTooManySessions
+			}
+			if err != nil {
+				m.emit(SessionEvent{State: SessionBindFailed, Err: err})
+			}
+		}()
+	}
+}
+
+func (m *Manager) outbind(parent net.Conn) (*Conn, error) {
+	conn := m.newConn(parent, BindReceiver)
+	conn.outbind = true
+	go conn.Watch()
+	timeout := m.setting.EnquiryTimeout
+	if timeout <= 0 {
+		timeout = time.Minute
+	}
+	var err error
+	select {
+	case packet := <-conn.PDU():
+		if p, ok := packet.(*pdu.Outbind); !ok {
+			err = ErrNotOutbind
+		} else if m.setting.OutbindAuthenticator != nil {
+			err = m.setting.OutbindAuthenticator(p.SystemID, p.Password)
+		}
+	case <-conn.Done():
+		err = ErrConnectionClosed
+	case <-time.After(timeout):
+		err = context.DeadlineExceeded
+	}
+	if err == nil {
+		_, err = conn.Bind(context.Background(), BindReceiver.packet(m.setting.Auth, m.setting.SmppVersion))
+	}
+	if err != nil {
+		conn.terminate()
+		return nil, err
+	}
+	return conn, nil
+}
@@ -0,0 +1,140 @@
+package smpp_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sujit-baniya/smpp"
+	"github.com/sujit-baniya/smpp/constant"
+	"github.com/sujit-baniya/smpp/pdu"
+)
+
+// handover is a listener accepting the connections it is handed, so that an
+// SMSC can serve the connections it opened itself.
+type handover struct {
+	conns chan net.Conn
+	done  chan struct{}
+	once  sync.Once
+}
+
+func (l *handover) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case <-l.done:
+		return nil, net.ErrClosed
+	}
+}
+
+func (l *handover) Close() error {
+	l.once.Do(func() { close(l.done) })
+	return nil
+}
+
+func (l *handover) Addr() net.Addr {
+	return &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1)}
+}
+
+func TestServeOutbind(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	events := make(chan smpp.SessionEvent, 8)
+	receipts := make(chan *smpp.DeliveryReceipt, 1)
+	manager, err := smpp.NewManager(smpp.Setting{
+		Auth:          smpp.Auth{SystemID: "esme", Password: "secret"},
+		MaxConnection: 1,
+		OutbindAuthenticator: func(systemID, password string) error {
+			if systemID != "smsc" || password != "outbind" {
+				return pdu.ErrInvalidPassword
+			}
+			return nil
+		},
+		OnSessionEvent:    func(event smpp.SessionEvent) { events <- event },
+		OnDeliveryReceipt: func(receipt *smpp.DeliveryReceipt) error { receipts <- receipt; return nil },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer manager.Close()
+	go func() { _ = manager.ServeOutbind(listener) }()
+
+	var binds []smpp.Auth
+	var mu sync.Mutex
+	smsc := &smpp.Server{
+		SystemID: "smsc",
+		Authenticator: smpp.AuthenticatorFunc(func(_ net.Addr, mode smpp.BindMode, auth smpp.Auth) error {
+			mu.Lock()
+			defer mu.Unlock()
+			if mode != smpp.BindReceiver {
+				return pdu.ErrInvalidBindStatus
+			}
+			binds = append(binds, auth)
+			return nil
+		}),
+	}
+	accepted := &handover{conns: make(chan net.Conn), done: make(chan struct{})}
+	go func() { _ = smsc.Serve(accepted) }()
+	defer smsc.Close()
+	outbind := func(password string) smpp.SessionEvent {
+		t.Helper()
+		conn, err := net.Dial("tcp", listener.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		packet := &pdu.Outbind{SystemID: "smsc", Password: password}
+		packet.Header.Sequence = 1
+		if _, err = pdu.Marshal(conn, packet); err != nil {
+			t.Fatal(err)
+		}
+		accepted.conns <- conn
+		select {
+		case event := <-events:
+			return event
+		case <-time.After(5 * time.Second):
+			t.Fatal("no session event")
+		}
+		return smpp.SessionEvent{}
+	}
+
+	if event := outbind("wrong"); event.State != smpp.SessionBindFailed || !errors.Is(event.Err, pdu.ErrInvalidPassword) {
+		t.Fatalf("outbind with a wrong password: %+v", event)
+	}
+	if event := outbind("outbind"); event.State != smpp.SessionBound {
+		t.Fatalf("outbind: %+v", event)
+	}
+	mu.Lock()
+	if len(binds) != 1 || binds[0].SystemID != "esme" || binds[0].Password != "secret" {
+		t.Fatalf("binds %+v", binds)
+	}
+	mu.Unlock()
+
+	sessions := smsc.Sessions()
+	if len(sessions) != 1 {
+		t.Fatalf("%d sessions bound, want 1", len(sessions))
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err = sessions[0].Submit(ctx, &pdu.DeliverSM{
+		ESMClass: pdu.ESMClass{MessageType: 1},
+		Tags: pdu.Tags{
+			constant.RECEIPTED_MESSAGE_ID: []byte("42\x00"),
+			constant.DR_MESSAGE_STATE:     {byte(pdu.MessageStateDelivered)},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if receipt := <-receipts; receipt.MessageID != "42" {
+		t.Fatalf("receipt %+v", receipt)
+	}
+
+	if event := outbind("outbind"); event.State != smpp.SessionBindFailed || !errors.Is(event.Err, smpp.ErrTooManySessions) {
+		t.Fatalf("outbind beyond MaxConnection: %+v", event)
+	}
+}
@@ -0,0 +1,152 @@
+package pdu
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"time"
+)
+
+// BroadcastAreaFormat see SMPP v5, section 4.8.4.4 (135p)
+type BroadcastAreaFormat byte
+
+const (
+	BroadcastAreaName         BroadcastAreaFormat = iota // alias or name agreed with the SMSC
+	BroadcastAreaEllipsoidArc                            // ellipsoid arc, 3GPP TS 23.032
+	BroadcastAreaPolygon                                 // polygon, 3GPP TS 23.032
+)
+
+// BroadcastArea is the value of a broadcast_area_identifier TLV.
+type BroadcastArea struct {
+	Format  BroadcastAreaFormat
+	Details []byte
+}
+
+// NamedBroadcastArea returns the area known to the SMSC as name.
+func NamedBroadcastArea(name string) BroadcastArea {
+	return BroadcastArea{Format: BroadcastAreaName, Details: []byte(name)}
+}
+
+func (a *BroadcastArea) From(value []byte) error {
+	if len(value) == 0 {
+		return ErrInvalidTagLength
+	}
+	a.Format, a.Details = BroadcastAreaFormat(value[0]), append([]byte(nil), value[1:]...)
+	return nil
+}
+
+func (a BroadcastArea) Bytes() []byte {
+	return append([]byte{byte(a.Format)}, a.Details...)
+}
+
+func (a BroadcastArea) String() string {
+	switch a.Format {
+	case BroadcastAreaName:
+		return string(a.Details)
+	case BroadcastAreaEllipsoidArc:
+		return "ellipsoid-arc:" + hex.EncodeToString(a.Details)
+	case BroadcastAreaPolygon:
+		return "polygon:" + hex.EncodeToString(a.Details)
+	}
+	return hex.EncodeToString(a.Bytes())
+}
+
+// BroadcastNetwork is the network type of a broadcast_content_type.
+type BroadcastNetwork byte
+
+const (
+	BroadcastNetworkGeneric BroadcastNetwork = iota
+	BroadcastNetworkGSM
+	BroadcastNetworkTDMA
+	BroadcastNetworkCDMA
+)
+
+const (
+	BroadcastServiceIndex     uint16 = 0x0000
+	BroadcastServiceEmergency uint16 = 0x0001
+)
+
+// BroadcastContentType see SMPP v5, section 4.8.4.6 (137p)
+type BroadcastContentType struct {
+	Network BroadcastNetwork
+	Service uint16
+}
+
+func (c *BroadcastContentType) From(value []byte) error {
+	if len(value) != 3 {
+		return ErrInvalidTagLength
+	}
+	c.Network, c.Service = BroadcastNetwork(value[0]), binary.BigEndian.Uint16(value[1:])
+	return nil
+}
+
+func (c BroadcastContentType) Bytes() []byte {
+	return []byte{byte(c.Network), byte(c.Service >> 8), byte(c.Service)}
+}
+
+// BroadcastUnit is the time unit of a broadcast_frequency_interval.
+type BroadcastUnit byte
+
+const (
+	BroadcastAsFrequentlyAsPossible BroadcastUnit = 0x00
+	BroadcastSeconds                BroadcastUnit = 0x08
+	BroadcastMinutes                BroadcastUnit = 0x09
+	BroadcastHours                  BroadcastUnit = 0x0A
+	BroadcastDays                   BroadcastUnit = 0x0B
+	BroadcastWeeks                  BroadcastUnit = 0x0C
+	BroadcastMonths                 BroadcastUnit = 0x0D
+	BroadcastYears                  BroadcastUnit = 0x0E
+)
+
+var broadcastUnits = []struct {
+	unit     BroadcastUnit
+	duration time.Duration
+}{
+	{BroadcastWeeks, 7 * 24 * time.Hour},
+	{BroadcastDays, 24 * time.Hour},
+	{BroadcastHours, time.Hour},
+	{BroadcastMinutes, time.Minute},
+	{BroadcastSeconds, time.Second},
+}
+
+// BroadcastFrequency see SMPP v5, section 4.8.4.9 (139p)
+type BroadcastFrequency struct {
+	Unit  BroadcastUnit
+	Value uint16
+}
+
+// BroadcastInterval returns the frequency repeating a broadcast every d, in
+// the largest unit that represents it exactly or else, rounded down, in the
+// smallest unit it fits in. Less than a second means as frequently as
+// possible.
+func BroadcastInterval(d time.Duration) BroadcastFrequency {
+	if d < time.Second {
+		return BroadcastFrequency{Unit: BroadcastAsFrequentlyAsPossible}
+	}
+	for _, u := range broadcastUnits {
+		if d%u.duration == 0 && d/u.duration <= 0xFFFF {
+			return BroadcastFrequency{Unit: u.unit, Value: uint16(d / u.duration)}
+		}
+	}
+	for i := len(broadcastUnits) - 1; i >= 0; i-- {
+		if u := broadcastUnits[i]; d/u.duration <= 0xFFFF {
+			return BroadcastFrequency{Unit: u.unit, Value: uint16(d / u.duration)}
+		}
+	}
+	return BroadcastFrequency{Unit: BroadcastWeeks, Value: 0xFFFF}
+}
+
+func (f *BroadcastFrequency) From(value []byte) error {
+	if len(value) != 3 {
+		return ErrInvalidTagLength
+	}
+	f.Unit, f.Value = BroadcastUnit(value[0]), binary.BigEndian.Uint16(value[1:])
+	return nil
+}
+
+func (f BroadcastFrequency) Bytes() []byte {
+	return []byte{byte(f.Unit), byte(f.Value >> 8), byte(f.Value)}
+}
+
+// BroadcastSuccessUnknown is the broadcast_area_success of an area whose
+// success rate is not available.
+const BroadcastSuccessUnknown byte = 0xFF
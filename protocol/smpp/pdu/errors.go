@@ -14,13 +14,34 @@ var (
 	ErrUnparseableTime      = errors.New("pdu: unparseable time")
 	ErrShortMessageTooLarge = errors.New("pdu: encoded short message data exceeds size of 140 bytes")
 	ErrMultipartTooMuch     = errors.New("pdu: multipart sms too much (max 254 segments)")
+	ErrUnknownTag           = errors.New("pdu: unknown tag")
+	ErrStandardTag          = errors.New("pdu: standard tag cannot be redefined")
 )
 
 const (
-	ErrInvalidCommandLength CommandStatus = 0x002
-	ErrInvalidCommandID     CommandStatus = 0x003
-	ErrInvalidDestCount     CommandStatus = 0x033
-	ErrInvalidDestFlag      CommandStatus = 0x040
-	ErrInvalidTagLength     CommandStatus = 0x0C2
-	ErrUnknownError         CommandStatus = 0x0FF
+	ErrInvalidCommandLength  CommandStatus = 0x002
+	ErrInvalidCommandID      CommandStatus = 0x003
+	ErrInvalidBindStatus     CommandStatus = 0x004
+	ErrAlreadyBound          CommandStatus = 0x005
+	ErrSystemError           CommandStatus = 0x008
+	ErrInvalidMessageID      CommandStatus = 0x00C
+	ErrBindFailed            CommandStatus = 0x00D
+	ErrInvalidPassword       CommandStatus = 0x00E
+	ErrInvalidSystemID       CommandStatus = 0x00F
+	ErrCancelFailed          CommandStatus = 0x011
+	ErrReplaceFailed         CommandStatus = 0x013
+	ErrMessageQueueFull      CommandStatus = 0x014
+	ErrInvalidDestCount      CommandStatus = 0x033
+	ErrInvalidDestFlag       CommandStatus = 0x040
+	ErrThrottled             CommandStatus = 0x058
+	ErrTemporaryAppError     CommandStatus = 0x064
+	ErrQueryFailed           CommandStatus = 0x067
+	ErrInvalidTagLength      CommandStatus = 0x0C2
+	ErrMissingTag            CommandStatus = 0x0C3
+	ErrInvalidTagValue       CommandStatus = 0x0C4
+	ErrUnknownError          CommandStatus = 0x0FF
+	ErrInvalidBroadcastArea  CommandStatus = 0x109
+	ErrBroadcastFailed       CommandStatus = 0x10D
+	ErrBroadcastQueryFailed  CommandStatus = 0x10E
+	ErrBroadcastCancelFailed CommandStatus = 0x10F
 )
@@ -11,6 +11,10 @@ type CommandID uint32
 // CommandStatus see SMPP v5, section 4.7.6 (116p)
 type CommandStatus uint32
 
+func (c CommandID) IsResponse() bool {
+	return c&0x80000000 != 0
+}
+
 type Header struct {
 	CommandLength uint32
 	CommandID     CommandID
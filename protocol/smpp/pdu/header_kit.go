@@ -2,6 +2,7 @@ package pdu
 
 import (
 	"reflect"
+	"strconv"
 )
 
 func ReadSequence(packet interface{}) int32 {
@@ -37,3 +38,30 @@ func getHeader(packet interface{}) *Header {
 	}
 	return nil
 }
+
+func ReadCommandID(packet interface{}) CommandID {
+	if h := getHeader(packet); h != nil && h.CommandID != 0 {
+		return h.CommandID
+	}
+	t := reflect.TypeOf(packet)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Struct && t.NumField() > 0 {
+		parsed, _ := strconv.ParseUint(t.Field(0).Tag.Get(_ID), 16, 32)
+		return CommandID(parsed)
+	}
+	return 0
+}
+
+func WriteCommandID(packet interface{}, id CommandID) {
+	if h := getHeader(packet); h != nil {
+		h.CommandID = id
+	}
+}
+
+func WriteCommandStatus(packet interface{}, status CommandStatus) {
+	if h := getHeader(packet); h != nil {
+		h.CommandStatus = status
+	}
+}
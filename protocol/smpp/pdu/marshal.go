@@ -11,6 +11,7 @@ import (
 
 func unmarshal(r io.Reader, packet interface{}) (n int64, err error) {
 	buf := bufio.NewReader(r)
+	failed := false
 	v := reflect.ValueOf(packet)
 	if v.Kind() == reflect.Ptr {
 		v = v.Elem()
@@ -36,8 +37,15 @@ func unmarshal(r io.Reader, packet interface{}) (n int64, err error) {
 			switch v := (field.Addr().Interface()).(type) {
 			case *Header:
 				err = readHeaderFrom(buf, v)
-				if v.CommandStatus != 0 {
-					return
+				if err == nil && v.CommandStatus != 0 {
+					// The body of an error response is usually omitted, but
+					// some carry TLVs explaining the failure, such as the
+					// failed areas of a broadcast_sm_resp. It is read as far
+					// as it goes.
+					if _, e := buf.Peek(1); e != nil {
+						return
+					}
+					failed = true
 				}
 			case io.ByteWriter:
 				var value byte
@@ -52,7 +60,10 @@ func unmarshal(r io.Reader, packet interface{}) (n int64, err error) {
 			}
 		}
 		n = int64(buf.Size())
-		if err != nil {
+		if err != nil && failed {
+			err = nil
+			return
+		} else if err != nil {
 			err = ErrUnmarshalPDUFailed
 			return
 		}
@@ -82,17 +93,20 @@ func Marshal(w io.Writer, packet interface{}) (n int64, err error) {
 		case reflect.Array, reflect.Map, reflect.Slice, reflect.Struct:
 			switch v := field.Addr().Interface().(type) {
 			case *Header:
+				// the command ID comes from the type and is not written back:
+				// the packet belongs to the caller and may be read concurrently
+				header := *v
 				var parsed uint64
 				if value := p.Type().Field(i).Tag.Get(_ID); value != "" {
 					parsed, err = strconv.ParseUint(value, 16, 32)
-					v.CommandID = CommandID(parsed)
+					header.CommandID = CommandID(parsed)
 				}
 				if err == nil && v.Sequence > 0 {
-					_ = binary.Write(&buf, binary.BigEndian, v)
+					_ = binary.Write(&buf, binary.BigEndian, header)
 				} else {
 					err = ErrInvalidSequence
 				}
-				if v.CommandStatus != 0 {
+				if v.CommandStatus != 0 && !hasTags(p) {
 					goto write
 				}
 			case io.ByteReader:
@@ -112,8 +126,17 @@ func Marshal(w io.Writer, packet interface{}) (n int64, err error) {
 	}
 write:
 	if p.Field(0).Type() == reflect.TypeOf(Header{}) {
+		if buf.Len() > 0x10000 {
+			return 0, ErrInvalidCommandLength
+		}
 		data := buf.Bytes()
 		binary.BigEndian.PutUint32(data[0:4], uint32(buf.Len()))
 	}
 	return buf.WriteTo(w)
 }
+
+// hasTags reports whether a packet has optional parameters to send.
+func hasTags(p reflect.Value) bool {
+	field := p.FieldByName("Tags")
+	return field.IsValid() && (field.Kind() == reflect.Map || field.Kind() == reflect.Slice) && field.Len() > 0
+}
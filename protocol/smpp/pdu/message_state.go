@@ -8,6 +8,19 @@ import (
 // MessageState see SMPP v5, section 4.7.15 (127p)
 type MessageState byte
 
+const (
+	MessageStateScheduled MessageState = iota
+	MessageStateEnroute
+	MessageStateDelivered
+	MessageStateExpired
+	MessageStateDeleted
+	MessageStateUndeliverable
+	MessageStateAccepted
+	MessageStateUnknown
+	MessageStateRejected
+	MessageStateSkipped
+)
+
 //goland:noinspection SpellCheckingInspection
 var messageStateMap = []string{
 	"scheduled",
@@ -23,7 +36,7 @@ var messageStateMap = []string{
 }
 
 func (m MessageState) String() string {
-	if int(m) > len(messageStateMap) {
+	if int(m) >= len(messageStateMap) {
 		return strconv.Itoa(int(m))
 	}
 	return strings.ToUpper(messageStateMap[m])
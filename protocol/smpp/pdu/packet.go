@@ -123,7 +123,7 @@ type BroadcastSM struct {
 	ReplaceIfPresent     bool
 	PriorityFlag         byte
 	DefaultMessageID     byte
-	Tags                 Tags
+	Tags                 TagList
 }
 
 func (p *BroadcastSM) GetHeader() Header {
@@ -138,7 +138,7 @@ func (p *BroadcastSM) Resp() interface{} {
 type BroadcastSMResp struct {
 	Header    Header `id:"80000112"`
 	MessageID string
-	Tags      Tags
+	Tags      TagList
 }
 
 func (p *BroadcastSMResp) GetHeader() Header {
@@ -328,7 +328,7 @@ func (p *QueryBroadcastSM) Resp() interface{} {
 type QueryBroadcastSMResp struct {
 	Header    Header `id:"80000111"`
 	MessageID string
-	Tags      Tags
+	Tags      TagList
 }
 
 func (p *QueryBroadcastSMResp) GetHeader() Header {
@@ -356,7 +356,7 @@ type QuerySMResp struct {
 	MessageID    string
 	FinalDate    string
 	MessageState MessageState
-	ErrorCode    CommandStatus
+	ErrorCode    byte
 }
 
 func (p *QuerySMResp) GetHeader() Header {
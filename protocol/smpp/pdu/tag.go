@@ -17,7 +17,7 @@ func (t *Tags) ReadFrom(r io.Reader) (n int64, err error) {
 		err = binary.Read(r, binary.BigEndian, values[:])
 		if err == nil {
 			data = make([]byte, values[1])
-			_, err = r.Read(data)
+			_, err = io.ReadFull(r, data)
 		}
 		if err == nil {
 			tags[values[0]] = data
@@ -59,3 +59,89 @@ func (t Tags) WriteTo(w io.Writer) (n int64, err error) {
 	}
 	return buf.WriteTo(w)
 }
+
+// TLV is one optional parameter of a TagList.
+type TLV struct {
+	Tag   uint16
+	Value []byte
+}
+
+// TagList holds the optional parameters of PDUs in which a tag may occur
+// more than once, such as broadcast_area_identifier, in wire order.
+type TagList []TLV
+
+// Get returns the value of the first tag instance.
+func (l TagList) Get(tag uint16) []byte {
+	for _, tlv := range l {
+		if tlv.Tag == tag {
+			return tlv.Value
+		}
+	}
+	return nil
+}
+
+// Values returns the values of every tag instance.
+func (l TagList) Values(tag uint16) (values [][]byte) {
+	for _, tlv := range l {
+		if tlv.Tag == tag {
+			values = append(values, tlv.Value)
+		}
+	}
+	return
+}
+
+// Add appends an instance of tag.
+func (l *TagList) Add(tag uint16, value []byte) {
+	*l = append(*l, TLV{Tag: tag, Value: value})
+}
+
+// Set replaces every instance of tag with value.
+func (l *TagList) Set(tag uint16, value []byte) {
+	list := (*l)[:0]
+	for _, tlv := range *l {
+		if tlv.Tag != tag {
+			list = append(list, tlv)
+		}
+	}
+	*l = append(list, TLV{Tag: tag, Value: value})
+}
+
+func (l *TagList) ReadFrom(r io.Reader) (n int64, err error) {
+	var values [2]uint16
+	var list TagList
+	for {
+		err = binary.Read(r, binary.BigEndian, values[:])
+		if err == io.EOF {
+			err = nil
+			break
+		}
+		data := make([]byte, values[1])
+		if err == nil {
+			_, err = io.ReadFull(r, data)
+		}
+		if err != nil {
+			break
+		}
+		list = append(list, TLV{Tag: values[0], Value: data})
+	}
+	if len(list) > 0 {
+		*l = list
+	}
+	return
+}
+
+func (l TagList) WriteTo(w io.Writer) (n int64, err error) {
+	var buf bytes.Buffer
+	for _, tlv := range l {
+		length := len(tlv.Value)
+		if length == 0 {
+			continue
+		} else if length > 0xFFFF {
+			return 0, ErrInvalidTagLength
+		}
+		_ = binary.Write(&buf, binary.BigEndian, tlv.Tag)
+		_ = binary.Write(&buf, binary.BigEndian, uint16(length))
+		buf.Write(tlv.Value)
+	}
+	return buf.WriteTo(w)
+}
@@ -0,0 +1,360 @@
+package pdu
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Tag identifies an optional parameter, see SMPP v5, section 4.8 (133p)
+type Tag uint16
+
+// TagType is the value type of an optional parameter.
+type TagType byte
+
+const (
+	TagOctets  TagType = iota // octet string
+	TagCString                // NUL terminated string
+	TagUint8
+	TagUint16
+	TagUint32
+)
+
+// TagDefinition describes an optional parameter. Min and Max bound the value
+// length in octets, including the NUL of a TagCString; a zero Max leaves it
+// unbounded. Integer types have their own fixed length.
+type TagDefinition struct {
+	Tag  Tag
+	Name string
+	Type TagType
+	Min  int
+	Max  int
+}
+
+//goland:noinspection SpellCheckingInspection
+var standardTags = []TagDefinition{
+	{0x0005, "dest_addr_subunit", TagUint8, 0, 0},
+	{0x0006, "dest_network_type", TagUint8, 0, 0},
+	{0x0007, "dest_bearer_type", TagUint8, 0, 0},
+	{0x0008, "dest_telematics_id", TagUint16, 0, 0},
+	{0x000D, "source_addr_subunit", TagUint8, 0, 0},
+	{0x000E, "source_network_type", TagUint8, 0, 0},
+	{0x000F, "source_bearer_type", TagUint8, 0, 0},
+	{0x0010, "source_telematics_id", TagUint8, 0, 0},
+	{0x0017, "qos_time_to_live", TagUint32, 0, 0},
+	{0x0019, "payload_type", TagUint8, 0, 0},
+	{0x001D, "additional_status_info_text", TagCString, 1, 256},
+	{0x001E, "receipted_message_id", TagCString, 1, 65},
+	{0x0030, "ms_msg_wait_facilities", TagUint8, 0, 0},
+	{0x0201, "privacy_indicator", TagUint8, 0, 0},
+	{0x0202, "source_subaddress", TagOctets, 2, 23},
+	{0x0203, "dest_subaddress", TagOctets, 2, 23},
+	{0x0204, "user_message_reference", TagUint16, 0, 0},
+	{0x0205, "user_response_code", TagUint8, 0, 0},
+	{0x020A, "source_port", TagUint16, 0, 0},
+	{0x020B, "destination_port", TagUint16, 0, 0},
+	{0x020C, "sar_msg_ref_num", TagUint16, 0, 0},
+	{0x020D, "language_indicator", TagUint8, 0, 0},
+	{0x020E, "sar_total_segments", TagUint8, 0, 0},
+	{0x020F, "sar_segment_seqnum", TagUint8, 0, 0},
+	{0x0210, "sc_interface_version", TagUint8, 0, 0},
+	{0x0302, "callback_num_pres_ind", TagUint8, 0, 0},
+	{0x0303, "callback_num_atag", TagOctets, 0, 65},
+	{0x0304, "number_of_messages", TagUint8, 0, 0},
+	{0x0381, "callback_num", TagOctets, 4, 19},
+	{0x0420, "dpf_result", TagUint8, 0, 0},
+	{0x0421, "set_dpf", TagUint8, 0, 0},
+	{0x0422, "ms_availability_status", TagUint8, 0, 0},
+	{0x0423, "network_error_code", TagOctets, 3, 3},
+	{0x0424, "message_payload", TagOctets, 0, 0xFFFF},
+	{0x0425, "delivery_failure_reason", TagUint8, 0, 0},
+	{0x0426, "more_messages_to_send", TagUint8, 0, 0},
+	{0x0427, "message_state", TagUint8, 0, 0},
+	{0x0428, "congestion_state", TagUint8, 0, 0},
+	{0x0501, "ussd_service_op", TagUint8, 0, 0},
+	{0x0600, "broadcast_channel_indicator", TagUint8, 0, 0},
+	{0x0601, "broadcast_content_type", TagOctets, 3, 3},
+	{0x0602, "broadcast_content_type_info", TagOctets, 0, 255},
+	{0x0603, "broadcast_message_class", TagUint8, 0, 0},
+	{0x0604, "broadcast_rep_num", TagUint16, 0, 0},
+	{0x0605, "broadcast_frequency_interval", TagOctets, 3, 3},
+	{0x0606, "broadcast_area_identifier", TagOctets, 1, 100},
+	{0x0607, "broadcast_error_status", TagUint32, 0, 0},
+	{0x0608, "broadcast_area_success", TagUint8, 0, 0},
+	{0x0609, "broadcast_end_time", TagCString, 1, 17},
+	{0x060A, "broadcast_service_group", TagOctets, 0, 255},
+	{0x060B, "billing_identification", TagOctets, 0, 1024},
+	{0x060D, "source_network_id", TagCString, 7, 66},
+	{0x060E, "dest_network_id", TagCString, 7, 66},
+	{0x060F, "source_node_id", TagOctets, 6, 6},
+	{0x0610, "dest_node_id", TagOctets, 6, 6},
+	{0x0611, "dest_addr_np_resolution", TagUint8, 0, 0},
+	{0x0612, "dest_addr_np_information", TagOctets, 10, 10},
+	{0x0613, "dest_addr_np_country", TagOctets, 5, 5},
+	{0x1201, "display_time", TagUint8, 0, 0},
+	{0x1203, "sms_signal", TagUint16, 0, 0},
+	{0x1204, "ms_validity", TagOctets, 1, 4},
+	{0x130C, "alert_on_message_delivery", TagOctets, 0, 1},
+	{0x1380, "its_reply_type", TagUint8, 0, 0},
+	{0x1383, "its_session_info", TagOctets, 2, 2},
+}
+
+var tagRegistry = struct {
+	definitions map[Tag]TagDefinition
+	mu          sync.RWMutex
+}{definitions: make(map[Tag]TagDefinition)}
+
+func init() {
+	for _, definition := range standardTags {
+		tagRegistry.definitions[definition.Tag] = definition
+	}
+}
+
+// RegisterTag adds or replaces the definition of a vendor specific tag.
+// Standard tags cannot be redefined.
+func RegisterTag(definition TagDefinition) error {
+	for _, standard := range standardTags {
+		if standard.Tag == definition.Tag {
+			return ErrStandardTag
+		}
+	}
+	tagRegistry.mu.Lock()
+	defer tagRegistry.mu.Unlock()
+	tagRegistry.definitions[definition.Tag] = definition
+	return nil
+}
+
+// LookupTag returns the definition of tag.
+func LookupTag(tag Tag) (TagDefinition, bool) {
+	tagRegistry.mu.RLock()
+	defer tagRegistry.mu.RUnlock()
+	definition, ok := tagRegistry.definitions[tag]
+	return definition, ok
+}
+
+func (t Tag) String() string {
+	if definition, ok := LookupTag(t); ok && definition.Name != "" {
+		return definition.Name
+	}
+	return fmt.Sprintf("0x%04X", uint16(t))
+}
+
+// definition returns the definition of tag, or an unbounded octet string for
+// an unknown tag.
+func definition(tag Tag) TagDefinition {
+	if definition, ok := LookupTag(tag); ok {
+		return definition
+	}
+	return TagDefinition{Tag: tag, Type: TagOctets}
+}
+
+func (d TagDefinition) size() int {
+	switch d.Type {
+	case TagUint8:
+		return 1
+	case TagUint16:
+		return 2
+	case TagUint32:
+		return 4
+	}
+	return 0
+}
+
+// Validate checks the length of value.
+func (d TagDefinition) Validate(value []byte) error {
+	if size := d.size(); size > 0 {
+		if len(value) != size {
+			return ErrInvalidTagLength
+		}
+		return nil
+	}
+	if len(value) < d.Min || d.Max > 0 && len(value) > d.Max {
+		return ErrInvalidTagLength
+	}
+	if d.Type == TagCString && (len(value) == 0 || value[len(value)-1] != 0) {
+		return ErrInvalidTagValue
+	}
+	return nil
+}
+
+// Format renders value as its type reads, in hex when it is not valid.
+func (d TagDefinition) Format(value []byte) string {
+	if d.Validate(value) != nil {
+		return hex.EncodeToString(value)
+	}
+	switch d.Type {
+	case TagCString:
+		return strconv.Quote(string(value[:len(value)-1]))
+	case TagUint8, TagUint16, TagUint32:
+		n, _ := decodeUint(value)
+		return strconv.FormatUint(uint64(n), 10)
+	}
+	return hex.EncodeToString(value)
+}
+
+func decodeUint(value []byte) (uint32, error) {
+	switch len(value) {
+	case 1:
+		return uint32(value[0]), nil
+	case 2:
+		return uint32(binary.BigEndian.Uint16(value)), nil
+	case 4:
+		return binary.BigEndian.Uint32(value), nil
+	}
+	return 0, ErrInvalidTagLength
+}
+
+func encodeUint(tag Tag, n uint32) ([]byte, error) {
+	d, ok := LookupTag(tag)
+	if !ok {
+		return nil, ErrUnknownTag
+	}
+	size := d.size()
+	if size == 0 {
+		return nil, ErrInvalidTagValue
+	} else if size < 4 && n >= 1<<(8*size) {
+		return nil, ErrInvalidTagValue
+	}
+	value := make([]byte, 4)
+	binary.BigEndian.PutUint32(value, n)
+	return value[4-size:], nil
+}
+
+func decodeCString(tag Tag, value []byte) (string, error) {
+	d := definition(tag)
+	if err := d.Validate(value); err != nil {
+		return "", err
+	}
+	if d.Type == TagCString {
+		value = value[:len(value)-1]
+	}
+	return string(value), nil
+}
+
+func encodeCString(tag Tag, s string) ([]byte, error) {
+	d := definition(tag)
+	value := []byte(s)
+	if d.Type == TagCString {
+		value = append(value, 0)
+	} else if d.Type != TagOctets {
+		return nil, ErrInvalidTagValue
+	}
+	return value, d.Validate(value)
+}
+
+func formatTags(tags []TLV) string {
+	var b strings.Builder
+	for i, tlv := range tags {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		tag := Tag(tlv.Tag)
+		b.WriteString(tag.String())
+		b.WriteByte(':')
+		b.WriteString(definition(tag).Format(tlv.Value))
+	}
+	return b.String()
+}
+
+// Uint returns the value of an integer tag.
+func (t Tags) Uint(tag Tag) (uint32, error) {
+	value, ok := t[uint16(tag)]
+	if !ok {
+		return 0, ErrMissingTag
+	}
+	return decodeUint(value)
+}
+
+// SetUint sets an integer tag, encoded in the length its definition gives.
+func (t Tags) SetUint(tag Tag, n uint32) error {
+	value, err := encodeUint(tag, n)
+	if err == nil {
+		t[uint16(tag)] = value
+	}
+	return err
+}
+
+// CString returns the value of a string tag, without its NUL terminator.
+func (t Tags) CString(tag Tag) (string, error) {
+	value, ok := t[uint16(tag)]
+	if !ok {
+		return "", ErrMissingTag
+	}
+	return decodeCString(tag, value)
+}
+
+// SetCString sets a string or octet string tag, adding the NUL terminator
+// its definition requires.
+func (t Tags) SetCString(tag Tag, s string) error {
+	value, err := encodeCString(tag, s)
+	if err == nil {
+		t[uint16(tag)] = value
+	}
+	return err
+}
+
+// SetOctets sets a tag after checking value against its definition.
+func (t Tags) SetOctets(tag Tag, value []byte) error {
+	err := definition(tag).Validate(value)
+	if err == nil {
+		t[uint16(tag)] = value
+	}
+	return err
+}
+
+// String lists the tags by name with their decoded values, in tag order.
+func (t Tags) String() string {
+	list := make([]TLV, 0, len(t))
+	for tag, value := range t {
+		list = append(list, TLV{Tag: tag, Value: value})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Tag < list[j].Tag })
+	return formatTags(list)
+}
+
+// Uint returns the value of the first instance of an integer tag.
+func (l TagList) Uint(tag Tag) (uint32, error) {
+	for _, tlv := range l {
+		if tlv.Tag == uint16(tag) {
+			return decodeUint(tlv.Value)
+		}
+	}
+	return 0, ErrMissingTag
+}
+
+// SetUint replaces every instance of an integer tag.
+func (l *TagList) SetUint(tag Tag, n uint32) error {
+	value, err := encodeUint(tag, n)
+	if err == nil {
+		l.Set(uint16(tag), value)
+	}
+	return err
+}
+
+// CString returns the value of the first instance of a string tag.
+func (l TagList) CString(tag Tag) (string, error) {
+	for _, tlv := range l {
+		if tlv.Tag == uint16(tag) {
+			return decodeCString(tag, tlv.Value)
+		}
+	}
+	return "", ErrMissingTag
+}
+
+// SetCString replaces every instance of a string or octet string tag.
+func (l *TagList) SetCString(tag Tag, s string) error {
+	value, err := encodeCString(tag, s)
+	if err == nil {
+		l.Set(uint16(tag), value)
+	}
+	return err
+}
+
+// String lists the tags by name with their decoded values, in wire order.
+func (l TagList) String() string {
+	return formatTags(l)
+}
@@ -0,0 +1,113 @@
+package pdu
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestTagsTypedValues(t *testing.T) {
+	tags := Tags{}
+	if err := tags.SetUint(0x0427, 2); err != nil { // message_state
+		t.Fatal(err)
+	}
+	if err := tags.SetUint(0x0204, 0x1234); err != nil { // user_message_reference
+		t.Fatal(err)
+	}
+	if err := tags.SetCString(0x001E, "abc"); err != nil { // receipted_message_id
+		t.Fatal(err)
+	}
+	if !bytes.Equal(tags[0x0427], []byte{2}) || !bytes.Equal(tags[0x0204], []byte{0x12, 0x34}) {
+		t.Fatalf("integers encoded as % x and % x", tags[0x0427], tags[0x0204])
+	}
+	if !bytes.Equal(tags[0x001E], []byte("abc\x00")) {
+		t.Fatalf("receipted_message_id encoded as %q", tags[0x001E])
+	}
+	if n, err := tags.Uint(0x0204); err != nil || n != 0x1234 {
+		t.Fatalf("Uint = %d, %v", n, err)
+	}
+	if s, err := tags.CString(0x001E); err != nil || s != "abc" {
+		t.Fatalf("CString = %q, %v", s, err)
+	}
+	if _, err := tags.Uint(0x0425); !errors.Is(err, ErrMissingTag) {
+		t.Fatalf("missing tag: %v", err)
+	}
+	if got, want := tags.String(), `receipted_message_id:"abc" user_message_reference:4660 message_state:2`; got != want {
+		t.Fatalf("String = %s, want %s", got, want)
+	}
+}
+
+func TestTagsValidation(t *testing.T) {
+	tags := Tags{}
+	if err := tags.SetUint(0x0427, 256); !errors.Is(err, ErrInvalidTagValue) {
+		t.Fatalf("message_state 256: %v", err)
+	}
+	if err := tags.SetOctets(0x0423, []byte{1, 2}); !errors.Is(err, ErrInvalidTagLength) {
+		t.Fatalf("network_error_code of 2 octets: %v", err)
+	}
+	if err := tags.SetUint(0x1400, 1); !errors.Is(err, ErrUnknownTag) {
+		t.Fatalf("unregistered tag: %v", err)
+	}
+	tags[0x001E] = []byte("abc")
+	if _, err := tags.CString(0x001E); !errors.Is(err, ErrInvalidTagValue) {
+		t.Fatalf("string without NUL: %v", err)
+	}
+	if len(tags) != 1 {
+		t.Fatalf("invalid values were stored: %v", tags)
+	}
+}
+
+func TestTagListRepeated(t *testing.T) {
+	var list TagList
+	list.Add(0x0606, []byte{0, 1})
+	list.Add(0x0606, []byte{0, 2})
+	if err := list.SetUint(0x0604, 3); err != nil {
+		t.Fatal(err)
+	}
+	if values := list.Values(0x0606); len(values) != 2 || values[1][1] != 2 {
+		t.Fatalf("Values = %v", values)
+	}
+	if err := list.SetUint(0x0604, 4); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := list.Uint(0x0604); err != nil || n != 4 || len(list.Values(0x0604)) != 1 {
+		t.Fatalf("SetUint did not replace: %d, %v, %v", n, err, list)
+	}
+	var buf bytes.Buffer
+	if _, err := list.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var read TagList
+	if _, err := read.ReadFrom(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if read.String() != list.String() {
+		t.Fatalf("read back %s, want %s", read, list)
+	}
+}
+
+func TestRegisterTag(t *testing.T) {
+	if err := RegisterTag(TagDefinition{Tag: 0x0427, Name: "mine", Type: TagUint16}); !errors.Is(err, ErrStandardTag) {
+		t.Fatalf("standard tag redefined: %v", err)
+	}
+	vendor := TagDefinition{Tag: 0x1401, Name: "vendor_operator", Type: TagCString, Min: 1, Max: 8}
+	if err := RegisterTag(vendor); err != nil {
+		t.Fatal(err)
+	}
+	if d, ok := LookupTag(0x1401); !ok || d != vendor {
+		t.Fatalf("LookupTag = %+v, %v", d, ok)
+	}
+	tags := Tags{}
+	if err := tags.SetCString(0x1401, "op"); err != nil {
+		t.Fatal(err)
+	}
+	if err := tags.SetCString(0x1401, "too long name"); !errors.Is(err, ErrInvalidTagLength) {
+		t.Fatalf("over Max: %v", err)
+	}
+	if got := tags.String(); got != `vendor_operator:"op"` {
+		t.Fatalf("String = %s", got)
+	}
+	if got := Tag(0x1402).String(); got != "0x1402" {
+		t.Fatalf("unknown tag String = %s", got)
+	}
+}
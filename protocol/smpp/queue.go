@@ -0,0 +1,161 @@
+package smpp
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+
+	"github.com/rs/xid"
+)
+
+// QueuedMessage is a message waiting in a Queue. A message that failed for a
+// temporary reason is requeued with the segments the SMSC accepted, so that
+// only the others are submitted again, under the same concatenation
+// reference.
+type QueuedMessage struct {
+	ID        string          `json:"id"`
+	Request   SendRequest     `json:"message"`
+	Enqueued  time.Time       `json:"enqueued"`
+	Reference uint16          `json:"reference"`
+	Requeues  int             `json:"requeues,omitempty"`
+	Accepted  []QueuedSegment `json:"accepted,omitempty"`
+}
+
+// QueuedSegment is a segment of a queued message accepted by the SMSC.
+type QueuedSegment struct {
+	Index        int    `json:"index"`
+	MessageID    string `json:"message_id"`
+	ConnectionID string `json:"connection_id,omitempty"`
+}
+
+// DefaultMaxRequeues is the number of times a queued message is requeued
+// after a temporary failure, unless Setting.QueueMaxRequeues is set.
+const DefaultMaxRequeues = 10
+
+// Queue holds outbound messages until a worker submits them. Dequeue claims
+// the oldest message; a claimed message is either acknowledged once it has
+// been handled or released back to the head of the queue with Nack, which
+// keeps the given progress. Messages that are claimed but neither
+// acknowledged nor released when the process stops are to be delivered again.
+type Queue interface {
+	Enqueue(msg QueuedMessage) error
+	Dequeue(ctx context.Context) (QueuedMessage, error)
+	Ack(id string) error
+	Nack(msg QueuedMessage) error
+	Len() int
+	Close() error
+}
+
+// Enqueue stores req in Setting.Queue and returns its ID. It is submitted by
+// the queue workers once a transmitter is bound. A message that cannot be
+// composed into segments, such as one without recipient or too long, is
+// refused rather than stored.
+func (m *Manager) Enqueue(req SendRequest) (string, error) {
+	if m.setting.Queue == nil {
+		return "", ErrNoQueue
+	}
+	queued := QueuedMessage{ID: xid.New().String(), Request: req, Enqueued: time.Now(), Reference: uint16(rand.Intn(0xFFFF))}
+	if _, err := m.segments(req, queued.Reference); err != nil {
+		return "", err
+	}
+	if err := m.setting.Queue.Enqueue(queued); err != nil {
+		return "", err
+	}
+	return queued.ID, nil
+}
+
+// drain starts Setting.QueueWorkers workers submitting queued messages until
+// ctx ends.
+func (m *Manager) drain(ctx context.Context) {
+	m.mu.Lock()
+	if m.setting.Queue == nil || m.draining == ctx {
+		m.mu.Unlock()
+		return
+	}
+	m.draining = ctx
+	m.mu.Unlock()
+	workers := m.setting.QueueWorkers
+	if workers <= 0 {
+		workers = m.setting.MaxConnection
+	}
+	for i := 0; i < workers; i++ {
+		go m.work(ctx)
+	}
+}
+
+// work submits queued messages one at a time. A message that failed for a
+// reason the retry policy deems temporary, such as no bound connection, is
+// released back to the queue with the segments that were accepted and the
+// worker backs off before the next one. Once requeued more than
+// Setting.QueueMaxRequeues times, or after a permanent failure, its failed
+// segments are handed to OnDeadLetter and the message is acknowledged.
+// Failures for want of a bound connection are not counted as requeues, so
+// that messages keep building up while the SMSC is unreachable.
+func (m *Manager) work(ctx context.Context) {
+	queue := m.setting.Queue
+	backoff := m.setting.Retry.Backoff
+	limit := m.setting.QueueMaxRequeues
+	if limit <= 0 {
+		limit = DefaultMaxRequeues
+	}
+	for failures := 0; ; {
+		msg, err := queue.Dequeue(ctx)
+		if err != nil {
+			return
+		}
+		result, err := m.sendSegments(ctx, msg.ID, msg.Request, msg.Reference, msg.Accepted)
+		temporary := err != nil && (ctx.Err() != nil || m.setting.Retry.Retryable(err))
+		if temporary && ctx.Err() == nil && !unreachable(result, err) {
+			msg.Requeues++
+		}
+		if temporary && msg.Requeues <= limit {
+			msg.Accepted = acceptedSegments(result)
+			_ = queue.Nack(msg)
+			failures++
+			timer := time.NewTimer(backoff.Duration(failures))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+			continue
+		}
+		failures = 0
+		if result != nil {
+			m.deadLetter(msg.ID, result.Segments)
+		} else if err != nil && m.setting.Retry.OnDeadLetter != nil {
+			// not composed, so nothing was submitted
+			m.setting.Retry.OnDeadLetter(DeadLetter{QueueID: msg.ID, Err: err})
+		}
+		_ = queue.Ack(msg.ID)
+	}
+}
+
+// unreachable reports whether the segments that failed did so without
+// reaching the SMSC, for want of a bound connection.
+func unreachable(result *SendResult, err error) bool {
+	if result == nil {
+		return errors.Is(err, ErrNoConnection)
+	}
+	for _, segment := range result.Segments {
+		if segment.Err != nil && !errors.Is(segment.Err, ErrNoConnection) && !errors.Is(segment.Err, ErrSegmentSkipped) {
+			return false
+		}
+	}
+	return true
+}
+
+// acceptedSegments returns the segments of result the SMSC accepted.
+func acceptedSegments(result *SendResult) (accepted []QueuedSegment) {
+	if result == nil {
+		return
+	}
+	for _, segment := range result.Segments {
+		if segment.Err == nil && segment.MessageID != "" {
+			accepted = append(accepted, QueuedSegment{Index: segment.Index, MessageID: segment.MessageID, ConnectionID: segment.ConnectionID})
+		}
+	}
+	return
+}
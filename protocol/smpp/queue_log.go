@@ -0,0 +1,257 @@
+package smpp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"sort"
+	"sync"
+)
+
+// LogQueue is a Queue persisted as an append-only log of JSON records, one
+// per line, synced to disk before each call returns. Opening the log replays
+// it, so messages that were not acknowledged survive a restart, and rewrites
+// it without the acknowledged ones.
+type LogQueue struct {
+	path    string
+	file    *os.File
+	pending []QueuedMessage
+	claimed map[string]QueuedMessage
+	records int
+	ready   chan struct{}
+	closed  bool
+	mu      sync.Mutex
+}
+
+type logRecord struct {
+	Op      string         `json:"op"`
+	ID      string         `json:"id,omitempty"`
+	Message *QueuedMessage `json:"message,omitempty"`
+}
+
+const (
+	logEnqueue = "enqueue"
+	logNack    = "nack"
+	logAck     = "ack"
+)
+
+// OpenLogQueue opens the log at path, creating it if needed.
+func OpenLogQueue(path string) (*LogQueue, error) {
+	q := &LogQueue{path: path, claimed: make(map[string]QueuedMessage), ready: make(chan struct{})}
+	if err := q.replay(); err != nil {
+		return nil, err
+	}
+	if err := q.compact(); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+func (q *LogQueue) replay() error {
+	file, err := os.Open(q.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	defer file.Close()
+	index := make(map[string]int)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var record logRecord
+		if json.Unmarshal(scanner.Bytes(), &record) != nil {
+			// a torn write at the end of the log
+			continue
+		}
+		switch record.Op {
+		case logEnqueue:
+			if record.Message != nil {
+				index[record.Message.ID] = len(q.pending)
+				q.pending = append(q.pending, *record.Message)
+			}
+		case logNack:
+			if i, ok := index[record.ID]; ok && record.Message != nil {
+				q.pending[i] = *record.Message
+			}
+		case logAck:
+			if i, ok := index[record.ID]; ok {
+				q.pending[i].ID = ""
+				delete(index, record.ID)
+			}
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return err
+	}
+	pending := q.pending[:0]
+	for _, msg := range q.pending {
+		if msg.ID != "" {
+			pending = append(pending, msg)
+		}
+	}
+	q.pending = pending
+	return nil
+}
+
+// compact rewrites the log with the unacknowledged messages only, the claimed
+// ones first as they were dequeued earlier.
+func (q *LogQueue) compact() error {
+	tmp := q.path + ".tmp"
+	file, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	writer := bufio.NewWriter(file)
+	encoder := json.NewEncoder(writer)
+	var messages []QueuedMessage
+	for _, msg := range q.claimed {
+		messages = append(messages, msg)
+	}
+	sort.Slice(messages, func(i, j int) bool { return messages[i].Enqueued.Before(messages[j].Enqueued) })
+	messages = append(messages, q.pending...)
+	for i := range messages {
+		if err = encoder.Encode(logRecord{Op: logEnqueue, Message: &messages[i]}); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		err = writer.Flush()
+	}
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp, q.path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	if q.file != nil {
+		_ = q.file.Close()
+	}
+	q.file, err = os.OpenFile(q.path, os.O_APPEND|os.O_WRONLY, 0o600)
+	q.records = len(messages)
+	return err
+}
+
+func (q *LogQueue) append(record logRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	if _, err = q.file.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	q.records++
+	return q.file.Sync()
+}
+
+func (q *LogQueue) Enqueue(msg QueuedMessage) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return ErrQueueClosed
+	}
+	if err := q.append(logRecord{Op: logEnqueue, Message: &msg}); err != nil {
+		return err
+	}
+	q.pending = append(q.pending, msg)
+	q.signal()
+	return nil
+}
+
+// Dequeue claims the oldest message, waiting for one until ctx ends.
+func (q *LogQueue) Dequeue(ctx context.Context) (QueuedMessage, error) {
+	for {
+		q.mu.Lock()
+		if q.closed {
+			q.mu.Unlock()
+			return QueuedMessage{}, ErrQueueClosed
+		}
+		if len(q.pending) > 0 {
+			msg := q.pending[0]
+			q.pending = q.pending[1:]
+			q.claimed[msg.ID] = msg
+			q.mu.Unlock()
+			return msg, nil
+		}
+		ready := q.ready
+		q.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			return QueuedMessage{}, ctx.Err()
+		case <-ready:
+		}
+	}
+}
+
+// Ack removes a claimed message for good. A log that has grown much larger
+// than the messages left in it is rewritten with them only.
+func (q *LogQueue) Ack(id string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return ErrQueueClosed
+	}
+	if _, ok := q.claimed[id]; !ok {
+		return nil
+	}
+	if err := q.append(logRecord{Op: logAck, ID: id}); err != nil {
+		return err
+	}
+	delete(q.claimed, id)
+	if live := len(q.pending) + len(q.claimed); q.records > 1024 && q.records > 4*live {
+		return q.compact()
+	}
+	return nil
+}
+
+// Nack puts a claimed message back at the head of the queue, logging its
+// progress first. The message is released even if that fails.
+func (q *LogQueue) Nack(msg QueuedMessage) (err error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if _, ok := q.claimed[msg.ID]; !ok {
+		return nil
+	}
+	if q.closed {
+		err = ErrQueueClosed
+	} else {
+		err = q.append(logRecord{Op: logNack, ID: msg.ID, Message: &msg})
+	}
+	delete(q.claimed, msg.ID)
+	q.pending = append([]QueuedMessage{msg}, q.pending...)
+	q.signal()
+	return err
+}
+
+// Len returns the number of messages not yet acknowledged.
+func (q *LogQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.pending) + len(q.claimed)
+}
+
+func (q *LogQueue) Close() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return nil
+	}
+	q.closed = true
+	q.signal()
+	return q.file.Close()
+}
+
+// signal wakes the goroutines waiting in Dequeue.
+func (q *LogQueue) signal() {
+	close(q.ready)
+	q.ready = make(chan struct{})
+}
@@ -0,0 +1,130 @@
+package smpp_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sujit-baniya/smpp"
+)
+
+func openLogQueue(t *testing.T, path string) *smpp.LogQueue {
+	t.Helper()
+	queue, err := smpp.OpenLogQueue(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return queue
+}
+
+func dequeue(t *testing.T, queue smpp.Queue) smpp.QueuedMessage {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	msg, err := queue.Dequeue(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return msg
+}
+
+func TestLogQueueReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.log")
+	queue := openLogQueue(t, path)
+	for _, id := range []string{"a", "b", "c", "d"} {
+		if err := queue.Enqueue(smpp.QueuedMessage{ID: id, Request: smpp.SendRequest{To: "491", Message: id}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := queue.Ack(dequeue(t, queue).ID); err != nil {
+		t.Fatal(err)
+	}
+	released := dequeue(t, queue)
+	released.Requeues = 1
+	released.Accepted = []smpp.QueuedSegment{{Index: 0, MessageID: "m1", ConnectionID: "c1"}}
+	if err := queue.Nack(released); err != nil {
+		t.Fatal(err)
+	}
+	dequeue(t, queue) // claimed again, neither acknowledged nor released
+	dequeue(t, queue) // c, claimed when the process stops
+	if err := queue.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	queue = openLogQueue(t, path)
+	defer queue.Close()
+	if n := queue.Len(); n != 3 {
+		t.Fatalf("%d messages replayed, want 3", n)
+	}
+	var ids []string
+	for i := 0; i < 3; i++ {
+		msg := dequeue(t, queue)
+		ids = append(ids, msg.ID)
+		if msg.ID == "b" && (msg.Requeues != 1 || len(msg.Accepted) != 1 || msg.Accepted[0].MessageID != "m1") {
+			t.Fatalf("progress of b lost: %+v", msg)
+		}
+	}
+	if strings.Join(ids, "") != "bcd" {
+		t.Fatalf("replayed %v, want b, c and d", ids)
+	}
+}
+
+func TestLogQueueCompaction(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.log")
+	queue := openLogQueue(t, path)
+	// one message stays in flight while others flow through, each adding an
+	// enqueue and an ack record
+	if err := queue.Enqueue(smpp.QueuedMessage{ID: "held"}); err != nil {
+		t.Fatal(err)
+	}
+	dequeue(t, queue)
+	var largest int64
+	for i := 1; ; i++ {
+		if err := queue.Enqueue(smpp.QueuedMessage{ID: strconv.Itoa(i)}); err != nil {
+			t.Fatal(err)
+		}
+		if err := queue.Ack(dequeue(t, queue).ID); err != nil {
+			t.Fatal(err)
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Size() < largest {
+			if 2*i < 1024 {
+				t.Fatalf("log compacted after %d records", 2*i)
+			}
+			break
+		}
+		largest = info.Size()
+		if i > 1024 {
+			t.Fatal("log never compacted while a message was in flight")
+		}
+	}
+	if err := queue.Enqueue(smpp.QueuedMessage{ID: "kept"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := queue.Enqueue(smpp.QueuedMessage{ID: "acked"}); err != nil {
+		t.Fatal(err)
+	}
+	dequeue(t, queue)
+	if err := queue.Ack(dequeue(t, queue).ID); err != nil {
+		t.Fatal(err)
+	}
+	_ = queue.Close()
+
+	queue = openLogQueue(t, path)
+	defer queue.Close()
+	data, _ := os.ReadFile(path)
+	if lines := bytes.Count(data, []byte("\n")); lines != 2 || !bytes.Contains(data, []byte(`"held"`)) || !bytes.Contains(data, []byte(`"kept"`)) {
+		t.Fatalf("log rewritten on open as\n%s", data)
+	}
+	if msg := dequeue(t, queue); msg.ID != "held" {
+		t.Fatalf("replayed %q first, want the message in flight", msg.ID)
+	}
+}
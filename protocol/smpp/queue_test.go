@@ -0,0 +1,152 @@
+package smpp_test
+
+import (
+	"errors"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sujit-baniya/smpp"
+	"github.com/sujit-baniya/smpp/pdu"
+	"github.com/sujit-baniya/smpp/smpptest"
+)
+
+func TestQueueRequeuesFailedSegments(t *testing.T) {
+	server := newServer(t)
+	queue := openLogQueue(t, filepath.Join(t.TempDir(), "queue.log"))
+	defer queue.Close()
+	letters := make(chan smpp.DeadLetter, 4)
+	manager := newManager(t, server, smpp.Setting{
+		Queue:            queue,
+		QueueWorkers:     1,
+		QueueMaxRequeues: 2,
+		Retry: smpp.RetryPolicy{
+			MaxAttempts:  1,
+			Backoff:      smpp.Backoff{Initial: time.Millisecond},
+			OnDeadLetter: func(letter smpp.DeadLetter) { letters <- letter },
+		},
+	})
+
+	server.Inject(smpptest.Fault{}, smpptest.Throttled)
+	if _, err := manager.Enqueue(smpp.SendRequest{From: "INFO", To: "491", Message: strings.Repeat("a", 200)}); err != nil {
+		t.Fatal(err)
+	}
+	eventually(t, func() bool { return queue.Len() == 0 })
+	submitted := server.Submitted()
+	if len(submitted) != 3 {
+		t.Fatalf("%d submit_sm for 2 parts with the second throttled once, want 3", len(submitted))
+	}
+	parts := make(map[uint8]int)
+	reference := submitted[0].Message.UDHeader.ConcatenatedHeader().Reference
+	for _, packet := range submitted {
+		header := packet.Message.UDHeader.ConcatenatedHeader()
+		if header.Reference != reference {
+			t.Fatalf("part %d requeued under reference %d, want %d", header.Sequence, header.Reference, reference)
+		}
+		parts[header.Sequence]++
+	}
+	if parts[1]+parts[2] != 3 || parts[1] == 0 || parts[2] == 0 {
+		t.Fatalf("parts submitted %v, want the throttled one twice", parts)
+	}
+
+	server.Inject(smpptest.Throttled, smpptest.Throttled, smpptest.Throttled)
+	id, err := manager.Enqueue(smpp.SendRequest{From: "INFO", To: "491", Message: "given up"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case letter := <-letters:
+		if letter.QueueID != id {
+			t.Fatalf("dead letter of %q, want %q", letter.QueueID, id)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("message not dead-lettered")
+	}
+	if got := len(server.Submitted()); got != 6 {
+		t.Fatalf("%d submit_sm, want 3 more for one send and two requeues", got)
+	}
+	if len(letters) != 0 || queue.Len() != 0 {
+		t.Fatalf("%d more dead letters, %d messages queued", len(letters), queue.Len())
+	}
+}
+
+func TestQueueKeepsMessagesWhileUnreachable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := listener.Addr().String()
+	_ = listener.Close()
+	queue := openLogQueue(t, filepath.Join(t.TempDir(), "queue.log"))
+	defer queue.Close()
+	letters := make(chan smpp.DeadLetter, 1)
+	manager, err := smpp.NewManager(smpp.Setting{
+		URL:               addr,
+		Queue:             queue,
+		QueueWorkers:      1,
+		QueueMaxRequeues:  2,
+		OnDeliveryReceipt: func(*smpp.DeliveryReceipt) error { return nil },
+		Retry: smpp.RetryPolicy{
+			Backoff:      smpp.Backoff{Initial: time.Millisecond, Max: time.Millisecond},
+			OnDeadLetter: func(letter smpp.DeadLetter) { letters <- letter },
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer manager.Close()
+	if err = manager.Start(); err == nil {
+		t.Fatal("bound to a closed port")
+	}
+	if _, err = manager.Enqueue(smpp.SendRequest{From: "INFO", To: "491", Message: "later"}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case letter := <-letters:
+		t.Fatalf("message dead-lettered while no connection was bound: %v", letter.Err)
+	case <-time.After(200 * time.Millisecond):
+	}
+	if n := queue.Len(); n != 1 {
+		t.Fatalf("%d messages queued, want 1", n)
+	}
+}
+
+func TestQueueDeadLettersUncomposable(t *testing.T) {
+	server := newServer(t)
+	queue := openLogQueue(t, filepath.Join(t.TempDir(), "queue.log"))
+	defer queue.Close()
+	letters := make(chan smpp.DeadLetter, 1)
+	manager := newManager(t, server, smpp.Setting{
+		Queue:        queue,
+		QueueWorkers: 1,
+		Retry:        smpp.RetryPolicy{OnDeadLetter: func(letter smpp.DeadLetter) { letters <- letter }},
+	})
+	if _, err := manager.Enqueue(smpp.SendRequest{From: "INFO", Message: "nobody"}); !errors.Is(err, smpp.ErrNoRecipient) {
+		t.Fatalf("enqueue without recipient: %v", err)
+	}
+	if _, err := manager.Enqueue(smpp.SendRequest{From: "INFO", To: "491", Message: strings.Repeat("a", 160*255)}); !errors.Is(err, pdu.ErrMultipartTooMuch) {
+		t.Fatalf("enqueue of too many parts: %v", err)
+	}
+	if n := queue.Len(); n != 0 {
+		t.Fatalf("%d refused messages stored", n)
+	}
+
+	// stored before the manager could check it, e.g. by an older version
+	if err := queue.Enqueue(smpp.QueuedMessage{ID: "stored", Request: smpp.SendRequest{From: "INFO", Message: "nobody"}}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case letter := <-letters:
+		if letter.QueueID != "stored" || letter.Packet != nil || !errors.Is(letter.Err, smpp.ErrNoRecipient) {
+			t.Fatalf("dead letter %+v", letter)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("message dropped without dead letter")
+	}
+	eventually(t, func() bool { return queue.Len() == 0 })
+	if n := len(server.Submitted()); n != 0 {
+		t.Fatalf("%d submit_sm", n)
+	}
+}
@@ -0,0 +1,96 @@
+package smpp
+
+import (
+	"context"
+	"time"
+)
+
+// SessionState is the state of a managed connection reported through
+// Setting.OnSessionEvent.
+type SessionState byte
+
+const (
+	SessionBound SessionState = iota
+	SessionClosed
+	SessionDisconnected
+	SessionReconnecting
+	SessionBindFailed
+)
+
+func (s SessionState) String() string {
+	switch s {
+	case SessionBound:
+		return "bound"
+	case SessionClosed:
+		return "closed"
+	case SessionDisconnected:
+		return "disconnected"
+	case SessionReconnecting:
+		return "reconnecting"
+	case SessionBindFailed:
+		return "bind failed"
+	}
+	return "unknown"
+}
+
+type SessionEvent struct {
+	ConnectionID string
+	State        SessionState
+	Attempt      int
+	Err          error
+	Time         time.Time
+}
+
+func (m *Manager) emit(event SessionEvent) {
+	if m.setting.OnSessionEvent != nil {
+		event.Time = time.Now()
+		m.setting.OnSessionEvent(event)
+	}
+}
+
+// watch waits for conn to die. A connection that is still registered was not
+// closed through the manager, so it is dropped and, with AutoRebind, replaced.
+// Connections opened by outbind are left for the SMSC to reopen.
+func (m *Manager) watch(ctx context.Context, conn *Conn) {
+	<-conn.Done()
+	m.mu.Lock()
+	_, managed := m.connections[conn.ID]
+	if managed {
+		m.unregister(conn.ID)
+	}
+	m.mu.Unlock()
+	if !managed {
+		m.emit(SessionEvent{ConnectionID: conn.ID, State: SessionClosed, Err: conn.Err()})
+		return
+	}
+	m.emit(SessionEvent{ConnectionID: conn.ID, State: SessionDisconnected, Err: conn.Err()})
+	if m.setting.AutoRebind && !conn.outbind {
+		m.reconnect(ctx, conn.Mode)
+	}
+}
+
+// reconnect dials and binds a replacement connection, waiting between
+// attempts according to Setting.RebindBackoff, until it succeeds or ctx ends.
+func (m *Manager) reconnect(ctx context.Context, mode BindMode) {
+	for attempt := 1; ; attempt++ {
+		m.emit(SessionEvent{State: SessionReconnecting, Attempt: attempt})
+		timer := time.NewTimer(m.setting.RebindBackoff.Duration(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		conn, err := m.bind(mode)
+		if err != nil {
+			m.emit(SessionEvent{State: SessionBindFailed, Attempt: attempt, Err: err})
+			continue
+		}
+		if ctx.Err() != nil {
+			_ = conn.Close()
+			return
+		}
+		m.register(ctx, conn)
+		return
+	}
+}
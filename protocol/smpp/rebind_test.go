@@ -0,0 +1,35 @@
+package smpp_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sujit-baniya/smpp"
+)
+
+func TestAutoRebind(t *testing.T) {
+	server := newServer(t)
+	events := make(chan smpp.SessionEvent, 16)
+	manager := newManager(t, server, smpp.Setting{
+		AutoRebind:     true,
+		RebindBackoff:  smpp.Backoff{Initial: 10 * time.Millisecond},
+		OnSessionEvent: func(event smpp.SessionEvent) { events <- event },
+	})
+	if event := <-events; event.State != smpp.SessionBound {
+		t.Fatalf("first event %v", event.State)
+	}
+	server.Drop()
+	for _, want := range []smpp.SessionState{smpp.SessionDisconnected, smpp.SessionReconnecting, smpp.SessionBound} {
+		select {
+		case event := <-events:
+			if event.State != want {
+				t.Fatalf("got %v, want %v", event.State, want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("no %v event", want)
+		}
+	}
+	if _, err := manager.Send(smpp.SendRequest{From: "INFO", To: "491", Message: "rebound"}); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,218 @@
+package smpp
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sujit-baniya/smpp/constant"
+	"github.com/sujit-baniya/smpp/pdu"
+)
+
+// esm_class message types of delivery receipts, see SMPP v5, section 4.7.12
+const (
+	esmDeliveryReceipt     = 0x01
+	esmIntermediateReceipt = 0x08
+)
+
+// DeliveryReceipt is a delivery receipt or intermediate notification sent by
+// the SMSC for a submitted message. Dates have no time zone in the receipt
+// text and are read as UTC.
+type DeliveryReceipt struct {
+	MessageID    string
+	State        pdu.MessageState
+	Submitted    int // number of short messages originally submitted
+	Delivered    int // number of short messages delivered
+	SubmitDate   time.Time
+	DoneDate     time.Time
+	Err          string // err field of the text, or the code of network_error_code
+	NetworkType  byte   // network type of network_error_code, zero without it
+	Text         string // first characters of the message
+	Intermediate bool
+	From         string
+	To           string
+	ConnectionID string
+	PDU          interface{}
+}
+
+// Final reports whether the receipt carries the final state of the message.
+func (r *DeliveryReceipt) Final() bool {
+	switch r.State {
+	case pdu.MessageStateDelivered, pdu.MessageStateExpired, pdu.MessageStateDeleted,
+		pdu.MessageStateUndeliverable, pdu.MessageStateAccepted, pdu.MessageStateRejected,
+		pdu.MessageStateSkipped:
+		return !r.Intermediate
+	}
+	return false
+}
+
+// String formats the receipt as the text of SMPP v5, Appendix B.
+func (r *DeliveryReceipt) String() string {
+	return fmt.Sprintf(
+		"id:%s sub:%03d dlvrd:%03d submit date:%s done date:%s stat:%s err:%s text:%s",
+		r.MessageID, r.Submitted, r.Delivered, r.SubmitDate.Format("0601021504"),
+		r.DoneDate.Format("0601021504"), ReceiptStat(r.State), r.Err, r.Text,
+	)
+}
+
+//goland:noinspection SpellCheckingInspection
+var receiptStats = map[pdu.MessageState]string{
+	pdu.MessageStateScheduled:     "SCHEDLD",
+	pdu.MessageStateEnroute:       "ENROUTE",
+	pdu.MessageStateDelivered:     "DELIVRD",
+	pdu.MessageStateExpired:       "EXPIRED",
+	pdu.MessageStateDeleted:       "DELETED",
+	pdu.MessageStateUndeliverable: "UNDELIV",
+	pdu.MessageStateAccepted:      "ACCEPTD",
+	pdu.MessageStateUnknown:       "UNKNOWN",
+	pdu.MessageStateRejected:      "REJECTD",
+	pdu.MessageStateSkipped:       "SKIPPED",
+}
+
+// stat values seen from SMSCs besides the ones of Appendix B
+//
+//goland:noinspection SpellCheckingInspection
+var receiptStatAliases = map[string]pdu.MessageState{
+	"DELIVERED":     pdu.MessageStateDelivered,
+	"DELIVERD":      pdu.MessageStateDelivered,
+	"UNDELIVERED":   pdu.MessageStateUndeliverable,
+	"UNDELIVERABLE": pdu.MessageStateUndeliverable,
+	"FAILED":        pdu.MessageStateUndeliverable,
+	"ACCEPTED":      pdu.MessageStateAccepted,
+	"REJECTED":      pdu.MessageStateRejected,
+	"EXPIRE":        pdu.MessageStateExpired,
+	"SCHEDULED":     pdu.MessageStateScheduled,
+	"BUFFERED":      pdu.MessageStateEnroute,
+}
+
+// ReceiptStat returns the stat of state in receipt text.
+func ReceiptStat(state pdu.MessageState) string {
+	if stat, ok := receiptStats[state]; ok {
+		return stat
+	}
+	return "UNKNOWN"
+}
+
+func parseReceiptStat(stat string) (pdu.MessageState, bool) {
+	stat = strings.ToUpper(strings.TrimSpace(stat))
+	for state, name := range receiptStats {
+		if name == stat {
+			return state, true
+		}
+	}
+	state, ok := receiptStatAliases[stat]
+	return state, ok
+}
+
+var receiptField = regexp.MustCompile(`(?i)(?:^|\s)(id|sub|dlvrd|submit[ _]?date|done[ _]?date|stat|err|error|text)\s*:`)
+
+// ParseReceiptText parses the text of a delivery receipt, as given in SMPP
+// v5, Appendix B, and its common variants: keys in any case, underscores in
+// date keys, missing fields, dates with seconds or a four-digit year and
+// spelled out stat values.
+func ParseReceiptText(text string) (*DeliveryReceipt, error) {
+	matches := receiptField.FindAllStringSubmatchIndex(text, -1)
+	receipt := &DeliveryReceipt{State: pdu.MessageStateUnknown}
+	found := false
+	for i, match := range matches {
+		key := strings.ToLower(text[match[2]:match[3]])
+		end := len(text)
+		if i+1 < len(matches) && key != "text" {
+			end = matches[i+1][0]
+		}
+		value := strings.TrimSpace(text[match[1]:end])
+		switch strings.NewReplacer(" ", "", "_", "").Replace(key) {
+		case "id":
+			receipt.MessageID, found = value, true
+		case "sub":
+			receipt.Submitted, _ = strconv.Atoi(value)
+		case "dlvrd":
+			receipt.Delivered, _ = strconv.Atoi(value)
+		case "submitdate":
+			receipt.SubmitDate = parseReceiptDate(value)
+		case "donedate":
+			receipt.DoneDate = parseReceiptDate(value)
+		case "stat":
+			if state, ok := parseReceiptStat(value); ok {
+				receipt.State, found = state, true
+			}
+		case "err", "error":
+			receipt.Err = value
+		case "text":
+			receipt.Text = strings.TrimLeft(text[match[1]:], " ")
+		}
+		if key == "text" {
+			break
+		}
+	}
+	if !found {
+		return nil, ErrNotReceipt
+	}
+	return receipt, nil
+}
+
+func parseReceiptDate(value string) time.Time {
+	for _, layout := range []string{"0601021504", "060102150405", "200601021504", "20060102150405"} {
+		if len(value) == len(layout) {
+			if t, err := time.Parse(layout, value); err == nil {
+				return t
+			}
+		}
+	}
+	return time.Time{}
+}
+
+// ParseDeliveryReceipt reads the delivery receipt of a deliver_sm or
+// data_sm. The receipt text is used first; the receipted_message_id,
+// message_state and network_error_code TLVs complete it, or stand alone
+// when the text is missing or in another format.
+func ParseDeliveryReceipt(packet interface{}) (*DeliveryReceipt, error) {
+	var esm pdu.ESMClass
+	var tags pdu.Tags
+	var text string
+	var from, to pdu.Address
+	switch p := packet.(type) {
+	case *pdu.DeliverSM:
+		esm, tags, from, to = p.ESMClass, p.Tags, p.SourceAddr, p.DestAddr
+		if p.Message.DataCoding.Encoding() != nil {
+			text, _ = p.Message.Parse()
+		} else {
+			text = string(p.Message.Message)
+		}
+		if payload, ok := tags[constant.MESSAGE_PAYLOAD]; ok && len(p.Message.Message) == 0 {
+			text = string(payload)
+		}
+	case *pdu.DataSM:
+		esm, tags, from, to = p.ESMClass, p.Tags, p.SourceAddr, p.DestAddr
+		text = string(tags[constant.MESSAGE_PAYLOAD])
+	default:
+		return nil, ErrNotReceipt
+	}
+	if esm.MessageType != esmDeliveryReceipt && esm.MessageType != esmIntermediateReceipt {
+		return nil, ErrNotReceipt
+	}
+	receipt, err := ParseReceiptText(text)
+	if err != nil {
+		receipt = &DeliveryReceipt{State: pdu.MessageStateUnknown}
+	}
+	if receipt.MessageID == "" {
+		receipt.MessageID, _ = tags.CString(constant.RECEIPTED_MESSAGE_ID)
+	}
+	if state, e := tags.Uint(constant.DR_MESSAGE_STATE); e == nil && receipt.State == pdu.MessageStateUnknown {
+		receipt.State = pdu.MessageState(state)
+	}
+	if code := tags[constant.NETWORK_ERROR_CODE]; len(code) == 3 {
+		receipt.NetworkType = code[0]
+		if receipt.Err == "" {
+			receipt.Err = fmt.Sprintf("%03d", uint16(code[1])<<8|uint16(code[2]))
+		}
+	}
+	if receipt.MessageID == "" {
+		return nil, ErrNotReceipt
+	}
+	receipt.Intermediate = esm.MessageType == esmIntermediateReceipt
+	receipt.From, receipt.To, receipt.PDU = from.No, to.No, packet
+	return receipt, nil
+}
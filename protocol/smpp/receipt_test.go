@@ -0,0 +1,114 @@
+package smpp_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sujit-baniya/smpp"
+	"github.com/sujit-baniya/smpp/constant"
+	"github.com/sujit-baniya/smpp/pdu"
+)
+
+func TestParseReceiptText(t *testing.T) {
+	date := func(s string) time.Time {
+		d, _ := time.Parse("2006-01-02 15:04:05", s)
+		return d
+	}
+	tests := []struct {
+		name string
+		text string
+		want smpp.DeliveryReceipt
+	}{{
+		name: "appendix B",
+		text: "id:1a2b sub:001 dlvrd:001 submit date:2401311200 done date:2401311201 stat:DELIVRD err:000 text:Hello world",
+		want: smpp.DeliveryReceipt{
+			MessageID: "1a2b", State: pdu.MessageStateDelivered, Submitted: 1, Delivered: 1,
+			SubmitDate: date("2024-01-31 12:00:00"), DoneDate: date("2024-01-31 12:01:00"), Err: "000", Text: "Hello world",
+		},
+	}, {
+		name: "upper case keys, underscores and seconds",
+		text: "ID:77 SUB:1 DLVRD:0 SUBMIT_DATE:240131120005 DONE_DATE:240131120107 STAT:UNDELIV ERR:034",
+		want: smpp.DeliveryReceipt{
+			MessageID: "77", State: pdu.MessageStateUndeliverable, Submitted: 1,
+			SubmitDate: date("2024-01-31 12:00:05"), DoneDate: date("2024-01-31 12:01:07"), Err: "034",
+		},
+	}, {
+		name: "four-digit year and spelled out stat",
+		text: "id:x9 submit date:202401311200 done date:20240131120130 stat:DELIVERED",
+		want: smpp.DeliveryReceipt{
+			MessageID: "x9", State: pdu.MessageStateDelivered,
+			SubmitDate: date("2024-01-31 12:00:00"), DoneDate: date("2024-01-31 12:01:30"),
+		},
+	}, {
+		name: "missing fields and error key",
+		text: "id:5 stat:EXPIRED error:254",
+		want: smpp.DeliveryReceipt{MessageID: "5", State: pdu.MessageStateExpired, Err: "254"},
+	}, {
+		name: "text holding keys",
+		text: "id:6 stat:ACCEPTD text:id:7 stat:REJECTD",
+		want: smpp.DeliveryReceipt{MessageID: "6", State: pdu.MessageStateAccepted, Text: "id:7 stat:REJECTD"},
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := smpp.ParseReceiptText(test.text)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *got != test.want {
+				t.Fatalf("got %+v\nwant %+v", *got, test.want)
+			}
+		})
+	}
+	if _, err := smpp.ParseReceiptText("hello there"); !errors.Is(err, smpp.ErrNotReceipt) {
+		t.Fatalf("plain text: %v", err)
+	}
+}
+
+func TestReceiptStringRoundTrip(t *testing.T) {
+	receipt := smpp.DeliveryReceipt{
+		MessageID: "42", State: pdu.MessageStateRejected, Submitted: 1,
+		SubmitDate: time.Date(2024, 1, 31, 12, 0, 0, 0, time.UTC),
+		DoneDate:   time.Date(2024, 1, 31, 12, 5, 0, 0, time.UTC),
+		Err:        "011", Text: "abc",
+	}
+	got, err := smpp.ParseReceiptText(receipt.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *got != receipt {
+		t.Fatalf("got %+v\nwant %+v", *got, receipt)
+	}
+}
+
+func TestParseDeliveryReceiptTags(t *testing.T) {
+	packet := &pdu.DeliverSM{
+		SourceAddr: pdu.Address{No: "4915112345678"},
+		DestAddr:   pdu.Address{No: "INFO"},
+		ESMClass:   pdu.ESMClass{MessageType: 1},
+		Tags: pdu.Tags{
+			constant.RECEIPTED_MESSAGE_ID: []byte("abc\x00"),
+			constant.DR_MESSAGE_STATE:     {byte(pdu.MessageStateUndeliverable)},
+			constant.NETWORK_ERROR_CODE:   {3, 0x00, 0x22},
+		},
+	}
+	receipt, err := smpp.ParseDeliveryReceipt(packet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if receipt.MessageID != "abc" || receipt.State != pdu.MessageStateUndeliverable || !receipt.Final() {
+		t.Fatalf("receipt %+v", receipt)
+	}
+	if receipt.NetworkType != 3 || receipt.Err != "034" || receipt.From != "4915112345678" {
+		t.Fatalf("receipt %+v", receipt)
+	}
+
+	packet.ESMClass.MessageType = 8
+	if receipt, err = smpp.ParseDeliveryReceipt(packet); err != nil || !receipt.Intermediate || receipt.Final() {
+		t.Fatalf("intermediate notification %+v, %v", receipt, err)
+	}
+	packet.ESMClass.MessageType = 0
+	if _, err = smpp.ParseDeliveryReceipt(packet); !errors.Is(err, smpp.ErrNotReceipt) {
+		t.Fatalf("mobile originated message: %v", err)
+	}
+}
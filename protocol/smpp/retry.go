@@ -0,0 +1,179 @@
+package smpp
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"time"
+
+	"github.com/sujit-baniya/smpp/pdu"
+)
+
+// RetryPolicy decides whether a failed submit is attempted again. A request
+// is retried when it failed on the transport (closed or unbound connection,
+// missing connection or full window) or was rejected with one of the Retry
+// statuses, unless the status is listed as Permanent. Any other status is a
+// permanent failure. The zero value makes a single attempt.
+//
+// A response timeout is only retried with RetryTimeouts: the SMSC may have
+// accepted the message without answering in time, in which case another
+// attempt delivers it twice.
+type RetryPolicy struct {
+	MaxAttempts   int                 // attempts made in total, including the first
+	Backoff       Backoff             // delay between attempts
+	Retry         []pdu.CommandStatus // defaults to throttled, message queue full and system error
+	Permanent     []pdu.CommandStatus // never retried, takes precedence over Retry
+	RetryTimeouts bool                // also retry requests left unanswered, at the risk of duplicates
+	OnDeadLetter  func(letter DeadLetter)
+}
+
+var defaultRetryStatuses = []pdu.CommandStatus{pdu.ErrThrottled, pdu.ErrMessageQueueFull, pdu.ErrSystemError}
+
+// Attempt records the outcome of one submission of a segment.
+type Attempt struct {
+	Number       int
+	ConnectionID string
+	Start        time.Time
+	Duration     time.Duration
+	Err          error
+}
+
+// DeadLetter is a submit_sm or submit_multi that could not be submitted,
+// either because it was rejected permanently or because its attempts were
+// exhausted. QueueID is set for a segment of a queued message, which is dead
+// lettered once the message is given up rather than after each attempt. A
+// queued message that could not be composed into PDUs is dead-lettered
+// without Packet.
+type DeadLetter struct {
+	QueueID  string
+	Packet   pdu.Responsable
+	Attempts []Attempt
+	Err      error
+}
+
+// Retryable reports whether err is worth another attempt under the policy.
+func (p RetryPolicy) Retryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var status *StatusError
+	if errors.As(err, &status) {
+		for _, code := range p.Permanent {
+			if code == status.Status {
+				return false
+			}
+		}
+		retry := p.Retry
+		if retry == nil {
+			retry = defaultRetryStatuses
+		}
+		for _, code := range retry {
+			if code == status.Status {
+				return true
+			}
+		}
+		return false
+	}
+	if errors.Is(err, ErrResponseTimeout) {
+		return p.RetryTimeouts
+	}
+	if errors.Is(err, ErrConnectionClosed) || errors.Is(err, ErrUnbound) || errors.Is(err, ErrNoConnection) ||
+		errors.Is(err, ErrWindowFull) || errors.Is(err, io.EOF) {
+		return true
+	}
+	var ne net.Error
+	return errors.As(err, &ne)
+}
+
+// submit sends packet on a connection picked among connectionID, retrying
+// according to Setting.Retry. It returns the response along with the history
+// of attempts; a segment that finally fails is handed to OnDeadLetter.
+func (m *Manager) submit(ctx context.Context, packet pdu.Responsable, connectionID ...string) (resp interface{}, attempts []Attempt, err error) {
+	resp, attempts, err = m.retry(ctx, packet, false, connectionID...)
+	if err != nil && m.setting.Retry.OnDeadLetter != nil {
+		m.setting.Retry.OnDeadLetter(DeadLetter{Packet: packet, Attempts: attempts, Err: err})
+	}
+	return
+}
+
+// operate sends an operation on an earlier message, such as a query_sm, and
+// retries it like submit without dead-lettering it. Given connection IDs, it
+// fails with ErrOriginClosed rather than fall back to another session once
+// none of them is bound: the SMSC only knows the message on its own session.
+func (m *Manager) operate(ctx context.Context, packet pdu.Responsable, connectionID ...string) (interface{}, error) {
+	resp, _, err := m.retry(ctx, packet, len(connectionID) > 0, connectionID...)
+	return resp, err
+}
+
+// retry sends packet until it succeeds or Setting.Retry gives up. When
+// pinned, only the connections among connectionID are used.
+func (m *Manager) retry(ctx context.Context, packet pdu.Responsable, pinned bool, connectionID ...string) (resp interface{}, attempts []Attempt, err error) {
+	policy := m.setting.Retry
+	for number := 1; ; number++ {
+		attempt := Attempt{Number: number, Start: time.Now()}
+		resp, err = m.attempt(ctx, packet, &attempt, pinned, connectionID...)
+		attempt.Duration, attempt.Err = time.Since(attempt.Start), err
+		attempts = append(attempts, attempt)
+		if err == nil || number >= policy.MaxAttempts || !policy.Retryable(err) {
+			break
+		}
+		if waitErr := m.backoff(ctx, number); waitErr != nil {
+			err = waitErr
+			break
+		}
+	}
+	return
+}
+
+// deadLetter hands the segments that failed to OnDeadLetter.
+func (m *Manager) deadLetter(queueID string, segments []SegmentResult) {
+	if m.setting.Retry.OnDeadLetter == nil {
+		return
+	}
+	for _, segment := range segments {
+		if segment.Err != nil {
+			m.setting.Retry.OnDeadLetter(DeadLetter{QueueID: queueID, Packet: segment.Packet, Attempts: segment.Attempts, Err: segment.Err})
+		}
+	}
+}
+
+// backoff waits before the next attempt, unless ctx ends or the manager is
+// closed first.
+func (m *Manager) backoff(ctx context.Context, attempt int) error {
+	m.mu.RLock()
+	done := m.ctx.Done()
+	m.mu.RUnlock()
+	timer := time.NewTimer(m.setting.Retry.Backoff.Duration(attempt))
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-done:
+		return ErrConnectionClosed
+	case <-timer.C:
+		return nil
+	}
+}
+
+func (m *Manager) attempt(ctx context.Context, packet pdu.Responsable, attempt *Attempt, pinned bool, connectionID ...string) (interface{}, error) {
+	var conn *Conn
+	if pinned {
+		if conn = m.pinnedConnection(connectionID); conn == nil {
+			return nil, ErrOriginClosed
+		}
+	} else if picked, ok := m.GetConnection(connectionID...).(*Conn); ok && picked != nil {
+		conn = picked
+	} else {
+		return nil, ErrNoConnection
+	}
+	attempt.ConnectionID = conn.ID
+	return submitOn(ctx, conn, packet)
+}
+
+func submitOn(ctx context.Context, conn *Conn, packet pdu.Responsable) (interface{}, error) {
+	if err := conn.ThrottleContext(ctx); err != nil {
+		return nil, err
+	}
+	return conn.Submit(ctx, packet)
+}
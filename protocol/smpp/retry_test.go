@@ -0,0 +1,69 @@
+package smpp_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sujit-baniya/smpp"
+	"github.com/sujit-baniya/smpp/pdu"
+	"github.com/sujit-baniya/smpp/smpptest"
+)
+
+func TestSendRetry(t *testing.T) {
+	server := newServer(t)
+	var letters []smpp.DeadLetter
+	var mu sync.Mutex
+	manager := newManager(t, server, smpp.Setting{Retry: smpp.RetryPolicy{
+		MaxAttempts: 3,
+		Backoff:     smpp.Backoff{Initial: time.Millisecond},
+		OnDeadLetter: func(letter smpp.DeadLetter) {
+			mu.Lock()
+			letters = append(letters, letter)
+			mu.Unlock()
+		},
+	}})
+
+	server.Inject(smpptest.Throttled, smpptest.QueueFull)
+	result, err := manager.Send(smpp.SendRequest{From: "INFO", To: "491", Message: "retried"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if attempts := result.Segments[0].Attempts; len(attempts) != 3 || attempts[0].Err == nil || attempts[2].Err != nil {
+		t.Fatalf("attempts %+v", attempts)
+	}
+
+	server.Inject(smpptest.Fault{Status: pdu.ErrInvalidDestCount})
+	result, err = manager.Send(smpp.SendRequest{From: "INFO", To: "491", Message: "refused"})
+	if !errors.Is(err, pdu.ErrInvalidDestCount) || len(result.Segments[0].Attempts) != 1 {
+		t.Fatalf("permanent failure retried: %v, %+v", err, result.Segments[0].Attempts)
+	}
+
+	server.Inject(smpptest.Throttled, smpptest.Throttled, smpptest.Throttled)
+	if _, err = manager.Send(smpp.SendRequest{From: "INFO", To: "491", Message: "exhausted"}); !errors.Is(err, pdu.ErrThrottled) {
+		t.Fatalf("exhausted attempts: %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(letters) != 2 || len(letters[1].Attempts) != 3 || letters[0].QueueID != "" {
+		t.Fatalf("dead letters %+v", letters)
+	}
+}
+
+func TestSendRetryTimeouts(t *testing.T) {
+	for _, retry := range []bool{false, true} {
+		server := newServer(t)
+		manager := newManager(t, server, smpp.Setting{
+			ResponseTimeout: 100 * time.Millisecond,
+			Retry:           smpp.RetryPolicy{MaxAttempts: 2, RetryTimeouts: retry},
+		})
+		server.Inject(smpptest.Discarded)
+		_, err := manager.Send(smpp.SendRequest{From: "INFO", To: "491", Message: "unanswered"})
+		if submitted := len(server.Submitted()); retry && (err != nil || submitted != 2) {
+			t.Fatalf("timeout retried: %d submit_sm, %v", submitted, err)
+		} else if !retry && (!errors.Is(err, smpp.ErrResponseTimeout) || submitted != 1) {
+			t.Fatalf("timeout not retried: %d submit_sm, %v", submitted, err)
+		}
+	}
+}
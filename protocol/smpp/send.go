@@ -0,0 +1,180 @@
+package smpp
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/sujit-baniya/smpp/coding"
+	"github.com/sujit-baniya/smpp/pdu"
+)
+
+// SendRequest is a text message to submit, split into as many segments as
+// its length and data coding require.
+type SendRequest struct {
+	From    string
+	To      string
+	Message string
+	Options SendOptions
+}
+
+// Message is the former name of SendRequest.
+//
+// Deprecated: use SendRequest.
+type Message = SendRequest
+
+// SendResult reports the outcome of a SendRequest. When the request was
+// enqueued rather than sent, only QueueID is set. With Setting.Store, the
+// message is tracked under TrackingID, its QueueID if it was queued.
+type SendResult struct {
+	QueueID    string
+	TrackingID string
+	DataCoding coding.DataCoding
+	Segments   []SegmentResult
+}
+
+// SegmentResult is the outcome of one submit_sm.
+type SegmentResult struct {
+	Index        int
+	Packet       *pdu.SubmitSM
+	MessageID    string
+	Status       pdu.CommandStatus
+	Err          error
+	ConnectionID string
+	Submitted    time.Time
+	Duration     time.Duration
+	Attempts     []Attempt
+}
+
+// Err returns the error of the first failed segment.
+func (r *SendResult) Err() error {
+	for _, segment := range r.Segments {
+		if segment.Err != nil {
+			return segment.Err
+		}
+	}
+	return nil
+}
+
+// Partial reports whether some segments were accepted while others failed.
+func (r *SendResult) Partial() bool {
+	var accepted, failed bool
+	for _, segment := range r.Segments {
+		if segment.Err != nil {
+			failed = true
+		} else {
+			accepted = true
+		}
+	}
+	return accepted && failed
+}
+
+// MessageIDs returns the message IDs assigned by the SMSC, in segment order.
+// A failed segment has an empty ID.
+func (r *SendResult) MessageIDs() []string {
+	ids := make([]string, len(r.Segments))
+	for i, segment := range r.Segments {
+		ids[i] = segment.MessageID
+	}
+	return ids
+}
+
+func (m *Manager) send(ctx context.Context, id string, req SendRequest, connectionId ...string) (*SendResult, error) {
+	result, err := m.sendSegments(ctx, id, req, uint16(rand.Intn(0xFFFF)), nil, connectionId...)
+	if result != nil {
+		m.deadLetter("", result.Segments)
+	}
+	return result, err
+}
+
+// segments checks req and composes its segments, concatenated with
+// reference.
+func (m *Manager) segments(req SendRequest, reference uint16) ([]Segment, error) {
+	if req.To == "" {
+		return nil, ErrNoRecipient
+	}
+	dataCoding := bestCoding(req.Message, m.setting.SmppVersion)
+	if req.Options.DataCoding != nil {
+		dataCoding = *req.Options.DataCoding
+	}
+	return ComposeSegments(req.Message, dataCoding, m.setting.Concatenation, reference)
+}
+
+// sendSegments submits the segments of req, concatenated with reference,
+// except the accepted ones, which are reported as they were accepted. Failed
+// segments are left for the caller to dead-letter.
+func (m *Manager) sendSegments(ctx context.Context, id string, req SendRequest, reference uint16, accepted []QueuedSegment, connectionId ...string) (*SendResult, error) {
+	segments, err := m.segments(req, reference)
+	if err != nil {
+		return nil, err
+	}
+	result := &SendResult{Segments: make([]SegmentResult, len(segments))}
+	if len(segments) > 0 {
+		result.DataCoding = segments[0].Message.DataCoding
+	}
+	packets := make([]*pdu.SubmitSM, len(segments))
+	for i, segment := range segments {
+		packets[i] = m.Prepare(req.From, req.To, segment.Message)
+		packets[i].Tags = segment.Tags
+		req.Options.apply(packets[i])
+	}
+	for _, segment := range accepted {
+		if segment.Index < len(packets) {
+			result.Segments[segment.Index] = SegmentResult{
+				Index:        segment.Index,
+				Packet:       packets[segment.Index],
+				MessageID:    segment.MessageID,
+				ConnectionID: segment.ConnectionID,
+			}
+		}
+	}
+	result.TrackingID = m.trackSubmit(id, req, len(packets))
+	defer m.trackResult(result)
+	if m.setting.StickySegments && len(packets) > 1 {
+		m.sendSticky(ctx, result, packets, connectionId...)
+		return result, result.Err()
+	}
+	var wg sync.WaitGroup
+	for i, packet := range packets {
+		if result.Segments[i].MessageID != "" {
+			continue
+		}
+		wg.Add(1)
+		go func(i int, packet *pdu.SubmitSM) {
+			defer wg.Done()
+			result.Segments[i], _ = m.sendSegment(ctx, packet, connectionId...)
+			result.Segments[i].Index = i
+		}(i, packet)
+	}
+	wg.Wait()
+	return result, result.Err()
+}
+
+func (m *Manager) sendSegment(ctx context.Context, packet *pdu.SubmitSM, connectionId ...string) (SegmentResult, *pdu.SubmitSMResp) {
+	resp, attempts, err := m.retry(ctx, packet, false, connectionId...)
+	segment, r := segmentResult(packet, resp, attempts, err)
+	m.remember(segment.MessageID, segment.ConnectionID)
+	return segment, r
+}
+
+func segmentResult(packet *pdu.SubmitSM, resp interface{}, attempts []Attempt, err error) (SegmentResult, *pdu.SubmitSMResp) {
+	segment := SegmentResult{Packet: packet, Err: err, Attempts: attempts}
+	if n := len(attempts); n > 0 {
+		segment.ConnectionID = attempts[n-1].ConnectionID
+		segment.Submitted = attempts[0].Start
+		segment.Duration = attempts[n-1].Start.Add(attempts[n-1].Duration).Sub(segment.Submitted)
+	}
+	var status *StatusError
+	if errors.As(err, &status) {
+		segment.Status = status.Status
+	}
+	r, ok := resp.(*pdu.SubmitSMResp)
+	if ok {
+		segment.MessageID = r.MessageID
+	} else if err == nil {
+		segment.Err = statusError(resp)
+	}
+	return segment, r
+}
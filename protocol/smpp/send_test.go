@@ -0,0 +1,56 @@
+package smpp_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/sujit-baniya/smpp"
+	"github.com/sujit-baniya/smpp/pdu"
+	"github.com/sujit-baniya/smpp/smpptest"
+)
+
+func TestSendSegments(t *testing.T) {
+	server := newServer(t)
+	manager := newManager(t, server, smpp.Setting{})
+	result, err := manager.Send(smpp.SendRequest{From: "INFO", To: "4915112345678", Message: strings.Repeat("a", 200)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := result.MessageIDs()
+	if len(ids) != 2 || ids[0] == "" || ids[1] == "" || ids[0] == ids[1] {
+		t.Fatalf("message IDs %v", ids)
+	}
+	submitted := server.Submitted()
+	if len(submitted) != 2 {
+		t.Fatalf("%d submit_sm, want 2", len(submitted))
+	}
+	first, second := submitted[0].Message.UDHeader.ConcatenatedHeader(), submitted[1].Message.UDHeader.ConcatenatedHeader()
+	if first == nil || second == nil || first.Reference != second.Reference {
+		t.Fatalf("concatenation headers %+v and %+v", first, second)
+	}
+}
+
+func TestSendPartialFailure(t *testing.T) {
+	server := newServer(t)
+	manager := newManager(t, server, smpp.Setting{})
+	server.Inject(smpptest.Fault{}, smpptest.Throttled)
+	result, err := manager.Send(smpp.SendRequest{From: "INFO", To: "491", Message: strings.Repeat("a", 200)})
+	if !errors.Is(err, pdu.ErrThrottled) || !result.Partial() {
+		t.Fatalf("partial failure: %v", err)
+	}
+	var failed int
+	for _, segment := range result.Segments {
+		if segment.Err != nil {
+			failed++
+			if segment.Status != pdu.ErrThrottled || segment.MessageID != "" {
+				t.Fatalf("failed segment %+v", segment)
+			}
+		} else if segment.MessageID == "" || segment.ConnectionID == "" {
+			t.Fatalf("accepted segment %+v", segment)
+		}
+	}
+	if failed != 1 {
+		t.Fatalf("%d segments failed, want 1", failed)
+	}
+}
@@ -0,0 +1,338 @@
+package smpp
+
+import (
+	"context"
+	"errors"
+	"net"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/sujit-baniya/smpp/pdu"
+)
+
+// Authenticator decides whether a bind request is accepted. A returned
+// pdu.CommandStatus is sent back as the bind response status, any other
+// error is reported as ESME_RBINDFAIL.
+type Authenticator interface {
+	Authenticate(addr net.Addr, mode BindMode, auth Auth) error
+}
+
+type AuthenticatorFunc func(addr net.Addr, mode BindMode, auth Auth) error
+
+func (fn AuthenticatorFunc) Authenticate(addr net.Addr, mode BindMode, auth Auth) error {
+	return fn(addr, mode, auth)
+}
+
+// ServerHandler holds the callbacks invoked for the operations of a bound
+// session. A nil callback answers the request with ESME_RINVCMDID. Errors
+// that are a pdu.CommandStatus become the response status, ErrSkipResponse
+// leaves the request unanswered and any other error is reported as
+// ESME_RSYSERR.
+type ServerHandler struct {
+	Bound             func(session *Session)
+	Unbound           func(session *Session)
+	SubmitSM          func(session *Session, p *pdu.SubmitSM) (*pdu.SubmitSMResp, error)
+	SubmitMulti       func(session *Session, p *pdu.SubmitMulti) (*pdu.SubmitMultiResp, error)
+	DataSM            func(session *Session, p *pdu.DataSM) (*pdu.DataSMResp, error)
+	QuerySM           func(session *Session, p *pdu.QuerySM) (*pdu.QuerySMResp, error)
+	CancelSM          func(session *Session, p *pdu.CancelSM) error
+	ReplaceSM         func(session *Session, p *pdu.ReplaceSM) error
+	BroadcastSM       func(session *Session, p *pdu.BroadcastSM) (*pdu.BroadcastSMResp, error)
+	QueryBroadcastSM  func(session *Session, p *pdu.QueryBroadcastSM) (*pdu.QueryBroadcastSMResp, error)
+	CancelBroadcastSM func(session *Session, p *pdu.CancelBroadcastSM) error
+	PDU               func(session *Session, packet interface{}) (interface{}, error)
+}
+
+// Server is the SMSC side of SMPP: it accepts ESME connections, authenticates
+// their binds and serves each bound session through Handler.
+type Server struct {
+	Addr          string
+	SystemID      string
+	Authenticator Authenticator
+	Handler       ServerHandler
+	ReadTimeout   time.Duration
+	WriteTimeout  time.Duration
+	Throttle      int
+	listener      net.Listener
+	sessions      map[string]*Session
+	ctx           context.Context
+	cancel        context.CancelFunc
+	mu            sync.RWMutex
+}
+
+// Session is a connection accepted by a Server. Until the ESME binds, only
+// bind, enquire_link and unbind are served.
+type Session struct {
+	*Conn
+	Auth    Auth
+	Version pdu.InterfaceVersion
+	bound   bool
+	mu      sync.RWMutex
+}
+
+func (s *Session) IsBound() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.bound
+}
+
+func (s *Session) RemoteAddr() net.Addr {
+	return s.parent.RemoteAddr()
+}
+
+func (s *Server) ListenAndServe() error {
+	listener, err := net.Listen("tcp", s.Addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(listener)
+}
+
+func (s *Server) Serve(listener net.Listener) error {
+	s.mu.Lock()
+	if s.ctx == nil {
+		s.ctx, s.cancel = context.WithCancel(context.Background())
+	}
+	if s.ctx.Err() != nil {
+		s.mu.Unlock()
+		return ErrServerClosed
+	}
+	s.listener = listener
+	s.sessions = make(map[string]*Session)
+	s.mu.Unlock()
+	for {
+		parent, err := listener.Accept()
+		if err != nil {
+			if s.ctx.Err() != nil {
+				return ErrServerClosed
+			}
+			var ne net.Error
+			if errors.As(err, &ne) && ne.Timeout() {
+				continue
+			}
+			return err
+		}
+		go s.serve(parent)
+	}
+}
+
+// Sessions returns the currently bound sessions.
+func (s *Server) Sessions() (sessions []*Session) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, session := range s.sessions {
+		if session.IsBound() {
+			sessions = append(sessions, session)
+		}
+	}
+	return
+}
+
+// Close stops accepting connections and unbinds every open session.
+func (s *Server) Close() (err error) {
+	s.mu.Lock()
+	if s.ctx == nil {
+		s.ctx, s.cancel = context.WithCancel(context.Background())
+	}
+	s.cancel()
+	listener := s.listener
+	var sessions []*Session
+	for _, session := range s.sessions {
+		sessions = append(sessions, session)
+	}
+	s.mu.Unlock()
+	if listener != nil {
+		err = listener.Close()
+	}
+	for _, session := range sessions {
+		_ = session.Close()
+	}
+	return
+}
+
+func (s *Server) serve(parent net.Conn) {
+	conn := NewConn(context.Background(), parent, s.Throttle)
+	if s.ReadTimeout > 0 {
+		conn.ReadTimeout = s.ReadTimeout
+	}
+	if s.WriteTimeout > 0 {
+		conn.WriteTimeout = s.WriteTimeout
+	}
+	session := &Session{Conn: conn}
+	s.mu.Lock()
+	s.sessions[conn.ID] = session
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.sessions, conn.ID)
+		s.mu.Unlock()
+		conn.terminate()
+		if session.IsBound() && s.Handler.Unbound != nil {
+			s.Handler.Unbound(session)
+		}
+	}()
+	go conn.Watch()
+	for {
+		select {
+		case <-conn.Done():
+			return
+		case packet, ok := <-conn.PDU():
+			if !ok {
+				return
+			}
+			s.handle(session, packet)
+		}
+	}
+}
+
+func (s *Server) handle(session *Session, packet interface{}) {
+	switch p := packet.(type) {
+	case *pdu.EnquireLink:
+		_ = session.Send(p.Resp())
+	case *pdu.BindTransceiver, *pdu.BindTransmitter, *pdu.BindReceiver:
+		s.bind(session, p.(pdu.Responsable))
+	default:
+		if !session.IsBound() {
+			if r, ok := packet.(pdu.Responsable); ok {
+				resp := r.Resp()
+				pdu.WriteCommandStatus(resp, pdu.ErrInvalidBindStatus)
+				_ = session.Send(resp)
+			}
+			return
+		}
+		go s.dispatch(session, packet)
+	}
+}
+
+func (s *Server) bind(session *Session, packet pdu.Responsable) {
+	mode, auth, version, _ := readBind(packet)
+	resp := packet.Resp()
+	var status pdu.CommandStatus
+	if session.IsBound() {
+		status = pdu.ErrAlreadyBound
+	} else if s.Authenticator != nil {
+		if err := s.Authenticator.Authenticate(session.RemoteAddr(), mode, auth); err != nil {
+			status = commandStatusOf(err, pdu.ErrBindFailed)
+		}
+	}
+	if status == 0 && s.SystemID != "" {
+		switch r := resp.(type) {
+		case *pdu.BindTransceiverResp:
+			r.SystemID = s.SystemID
+		case *pdu.BindTransmitterResp:
+			r.SystemID = s.SystemID
+		case *pdu.BindReceiverResp:
+			r.SystemID = s.SystemID
+		}
+	}
+	pdu.WriteCommandStatus(resp, status)
+	if err := session.Send(resp); err != nil || status != 0 {
+		return
+	}
+	session.mu.Lock()
+	session.Auth, session.Conn.Mode, session.Version, session.bound = auth, mode, version, true
+	session.mu.Unlock()
+	if s.Handler.Bound != nil {
+		s.Handler.Bound(session)
+	}
+}
+
+func (s *Server) dispatch(session *Session, packet interface{}) {
+	var resp interface{}
+	var err error
+	handled := true
+	h := s.Handler
+	switch p := packet.(type) {
+	case *pdu.SubmitSM, *pdu.SubmitMulti, *pdu.DataSM, *pdu.BroadcastSM, *pdu.ReplaceSM:
+		if !session.Mode.CanTransmit() {
+			err = pdu.ErrInvalidBindStatus
+			break
+		}
+		switch p := p.(type) {
+		case *pdu.SubmitSM:
+			handled = h.SubmitSM != nil
+			if handled {
+				resp, err = nonNil(h.SubmitSM(session, p))
+			}
+		case *pdu.SubmitMulti:
+			handled = h.SubmitMulti != nil
+			if handled {
+				resp, err = nonNil(h.SubmitMulti(session, p))
+			}
+		case *pdu.DataSM:
+			handled = h.DataSM != nil
+			if handled {
+				resp, err = nonNil(h.DataSM(session, p))
+			}
+		case *pdu.BroadcastSM:
+			handled = h.BroadcastSM != nil
+			if handled {
+				resp, err = nonNil(h.BroadcastSM(session, p))
+			}
+		case *pdu.ReplaceSM:
+			handled = h.ReplaceSM != nil
+			if handled {
+				err = h.ReplaceSM(session, p)
+			}
+		}
+	case *pdu.QuerySM:
+		handled = h.QuerySM != nil
+		if handled {
+			resp, err = nonNil(h.QuerySM(session, p))
+		}
+	case *pdu.CancelSM:
+		handled = h.CancelSM != nil
+		if handled {
+			err = h.CancelSM(session, p)
+		}
+	case *pdu.QueryBroadcastSM:
+		handled = h.QueryBroadcastSM != nil
+		if handled {
+			resp, err = nonNil(h.QueryBroadcastSM(session, p))
+		}
+	case *pdu.CancelBroadcastSM:
+		handled = h.CancelBroadcastSM != nil
+		if handled {
+			err = h.CancelBroadcastSM(session, p)
+		}
+	default:
+		handled = false
+	}
+	if !handled {
+		if h.PDU != nil {
+			resp, err = nonNil(h.PDU(session, packet))
+		} else {
+			err = pdu.ErrInvalidCommandID
+		}
+	}
+	r, ok := packet.(pdu.Responsable)
+	if !ok || errors.Is(err, ErrSkipResponse) {
+		return
+	}
+	if resp == nil {
+		resp = r.Resp()
+	}
+	pdu.WriteSequence(resp, pdu.ReadSequence(packet))
+	if err != nil {
+		pdu.WriteCommandStatus(resp, commandStatusOf(err, pdu.ErrSystemError))
+	}
+	_ = session.Send(resp)
+}
+
+// nonNil drops typed nil responses so that they are replaced by the default
+// response of the request.
+func nonNil(resp interface{}, err error) (interface{}, error) {
+	if v := reflect.ValueOf(resp); !v.IsValid() || v.Kind() == reflect.Ptr && v.IsNil() {
+		return nil, err
+	}
+	return resp, err
+}
+
+func commandStatusOf(err error, fallback pdu.CommandStatus) pdu.CommandStatus {
+	var status pdu.CommandStatus
+	if errors.As(err, &status) && status != 0 {
+		return status
+	}
+	return fallback
+}
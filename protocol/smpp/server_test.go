@@ -0,0 +1,149 @@
+package smpp_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sujit-baniya/smpp"
+	"github.com/sujit-baniya/smpp/pdu"
+)
+
+// openConn opens a watched connection to addr that is closed with the test.
+func openConn(t *testing.T, addr string) *smpp.Conn {
+	t.Helper()
+	conn, err := smpp.OpenConn(context.Background(), addr, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go conn.Watch()
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+func submit(conn *smpp.Conn, packet pdu.Responsable) (interface{}, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	return conn.Submit(ctx, packet)
+}
+
+func TestServer(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var mu sync.Mutex
+	var bound, unbound []smpp.Auth
+	server := &smpp.Server{
+		SystemID: "smsc",
+		Authenticator: smpp.AuthenticatorFunc(func(_ net.Addr, _ smpp.BindMode, auth smpp.Auth) error {
+			switch {
+			case auth.SystemID == "blocked":
+				return errors.New("blocked")
+			case auth.Password != "secret":
+				return pdu.ErrInvalidPassword
+			}
+			return nil
+		}),
+		Handler: smpp.ServerHandler{
+			Bound: func(session *smpp.Session) {
+				mu.Lock()
+				bound = append(bound, session.Auth)
+				mu.Unlock()
+			},
+			Unbound: func(session *smpp.Session) {
+				mu.Lock()
+				unbound = append(unbound, session.Auth)
+				mu.Unlock()
+			},
+			SubmitSM: func(session *smpp.Session, p *pdu.SubmitSM) (*pdu.SubmitSMResp, error) {
+				return &pdu.SubmitSMResp{MessageID: "m-" + p.DestAddr.No}, nil
+			},
+			DataSM: func(*smpp.Session, *pdu.DataSM) (*pdu.DataSMResp, error) {
+				return nil, errors.New("unexpected")
+			},
+			CancelSM: func(*smpp.Session, *pdu.CancelSM) error {
+				return pdu.ErrCancelFailed
+			},
+			ReplaceSM: func(*smpp.Session, *pdu.ReplaceSM) error {
+				return smpp.ErrSkipResponse
+			},
+		},
+	}
+	go func() { _ = server.Serve(listener) }()
+	addr := listener.Addr().String()
+
+	conn := openConn(t, addr)
+	if _, err = submit(conn, &pdu.SubmitSM{DestAddr: pdu.Address{No: "491"}}); !errors.Is(err, pdu.ErrInvalidBindStatus) {
+		t.Fatalf("submit before binding: %v", err)
+	}
+	if _, err = submit(conn, &pdu.BindTransmitter{SystemID: "esme", Password: "wrong"}); !errors.Is(err, pdu.ErrInvalidPassword) {
+		t.Fatalf("bind with a wrong password: %v", err)
+	}
+	if _, err = submit(conn, &pdu.BindTransmitter{SystemID: "blocked", Password: "secret"}); !errors.Is(err, pdu.ErrBindFailed) {
+		t.Fatalf("bind refused with a plain error: %v", err)
+	}
+	resp, err := submit(conn, &pdu.BindTransmitter{SystemID: "esme", Password: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id := resp.(*pdu.BindTransmitterResp).SystemID; id != "smsc" {
+		t.Fatalf("bound to %q", id)
+	}
+	if _, err = submit(conn, &pdu.BindTransmitter{SystemID: "esme", Password: "secret"}); !errors.Is(err, pdu.ErrAlreadyBound) {
+		t.Fatalf("second bind: %v", err)
+	}
+	if len(server.Sessions()) != 1 {
+		t.Fatalf("%d sessions", len(server.Sessions()))
+	}
+
+	if resp, err = submit(conn, &pdu.SubmitSM{DestAddr: pdu.Address{No: "491"}}); err != nil || resp.(*pdu.SubmitSMResp).MessageID != "m-491" {
+		t.Fatalf("submit_sm: %+v, %v", resp, err)
+	}
+	tests := []struct {
+		name   string
+		packet pdu.Responsable
+		err    error
+	}{
+		{"handler status", &pdu.CancelSM{MessageID: "m-491"}, pdu.ErrCancelFailed},
+		{"handler error", &pdu.DataSM{DestAddr: pdu.Address{No: "491"}}, pdu.ErrSystemError},
+		{"no handler", &pdu.QuerySM{MessageID: "m-491"}, pdu.ErrInvalidCommandID},
+		{"skipped response", &pdu.ReplaceSM{MessageID: "m-491"}, context.DeadlineExceeded},
+	}
+	for _, test := range tests {
+		if _, err = submit(conn, test.packet); !errors.Is(err, test.err) {
+			t.Errorf("%s: got %v, want %v", test.name, err, test.err)
+		}
+	}
+
+	receiver := openConn(t, addr)
+	if _, err = submit(receiver, &pdu.BindReceiver{SystemID: "reader", Password: "secret"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = submit(receiver, &pdu.SubmitSM{DestAddr: pdu.Address{No: "491"}}); !errors.Is(err, pdu.ErrInvalidBindStatus) {
+		t.Fatalf("submit on a receiver: %v", err)
+	}
+
+	if err = conn.Close(); err != nil {
+		t.Fatal(err)
+	}
+	eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(unbound) == 1
+	})
+	_ = server.Close()
+	eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(unbound) == 2
+	})
+	mu.Lock()
+	defer mu.Unlock()
+	if len(bound) != 2 || bound[0].SystemID != "esme" || unbound[0].SystemID != "esme" || unbound[1].SystemID != "reader" {
+		t.Fatalf("bound %+v, unbound %+v", bound, unbound)
+	}
+}
@@ -0,0 +1,135 @@
+package smpptest
+
+import (
+	"time"
+
+	"github.com/sujit-baniya/smpp"
+	"github.com/sujit-baniya/smpp/constant"
+	"github.com/sujit-baniya/smpp/pdu"
+)
+
+// broadcast is the state the Server keeps about an accepted broadcast_sm.
+type broadcast struct {
+	message
+	serviceType string
+	contentType []byte
+	areas       [][]byte
+}
+
+// Broadcasts returns the broadcast_sm PDUs received so far.
+func (s *Server) Broadcasts() []*pdu.BroadcastSM {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*pdu.BroadcastSM(nil), s.broadcasted...)
+}
+
+func (s *Server) broadcastSM(session *smpp.Session, p *pdu.BroadcastSM) (*pdu.BroadcastSMResp, error) {
+	s.mu.Lock()
+	s.broadcasted = append(s.broadcasted, p)
+	s.mu.Unlock()
+	if fault, ok := s.nextFault(); ok {
+		if err := s.apply(fault, session, p); err != nil {
+			return nil, err
+		}
+	}
+	areas := p.Tags.Values(constant.BROADCAST_AREA_IDENTIFIER)
+	if len(areas) == 0 {
+		return nil, pdu.ErrInvalidBroadcastArea
+	}
+	failed := &pdu.BroadcastSMResp{}
+	for _, value := range areas {
+		var area pdu.BroadcastArea
+		status := pdu.ErrInvalidBroadcastArea
+		if area.From(value) == nil {
+			status = 0
+			if s.AreaStatus != nil {
+				status = s.AreaStatus(area)
+			}
+		}
+		if status == 0 {
+			continue
+		}
+		if len(failed.Tags) == 0 {
+			_ = failed.Tags.SetUint(constant.BROADCAST_ERROR_STATUS, uint32(status))
+		}
+		failed.Tags.Add(constant.BROADCAST_AREA_IDENTIFIER, value)
+	}
+	if len(failed.Tags) > 0 {
+		return failed, pdu.ErrBroadcastFailed
+	}
+	id := s.nextMessageID()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.broadcasts == nil {
+		s.broadcasts = make(map[string]*broadcast)
+	}
+	s.broadcasts[id] = &broadcast{
+		message:     message{session: session.ID, from: p.SourceAddr.No, state: pdu.MessageStateEnroute},
+		serviceType: p.ServiceType,
+		contentType: p.Tags.Get(constant.BROADCAST_CONTENT_TYPE),
+		areas:       areas,
+	}
+	return &pdu.BroadcastSMResp{MessageID: id}, nil
+}
+
+func (s *Server) lookupBroadcast(session *smpp.Session, id string, unknown pdu.CommandStatus) (*broadcast, error) {
+	b, ok := s.broadcasts[id]
+	if !ok {
+		return nil, unknown
+	}
+	if s.RequireOrigin && b.session != session.ID {
+		return nil, pdu.ErrInvalidBindStatus
+	}
+	return b, nil
+}
+
+func (s *Server) queryBroadcastSM(session *smpp.Session, p *pdu.QueryBroadcastSM) (*pdu.QueryBroadcastSMResp, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, err := s.lookupBroadcast(session, p.MessageID, pdu.ErrBroadcastQueryFailed)
+	if err != nil {
+		return nil, err
+	}
+	resp := &pdu.QueryBroadcastSMResp{MessageID: p.MessageID}
+	resp.Tags.Add(constant.DR_MESSAGE_STATE, []byte{byte(b.state)})
+	for _, area := range b.areas {
+		resp.Tags.Add(constant.BROADCAST_AREA_IDENTIFIER, area)
+		resp.Tags.Add(constant.BROADCAST_AREA_SUCCESS, []byte{100})
+	}
+	if b.isFinal() {
+		_ = resp.Tags.SetCString(constant.BROADCAST_END_TIME, pdu.Time{Time: b.final}.String())
+	}
+	return resp, nil
+}
+
+func (s *Server) cancelBroadcastSM(session *smpp.Session, p *pdu.CancelBroadcastSM) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var targets []*broadcast
+	if p.MessageID != "" {
+		b, err := s.lookupBroadcast(session, p.MessageID, pdu.ErrBroadcastCancelFailed)
+		if err != nil {
+			return err
+		}
+		targets = append(targets, b)
+	} else {
+		contentType, filtered := p.Tags[constant.BROADCAST_CONTENT_TYPE]
+		for _, b := range s.broadcasts {
+			if b.from == p.SourceAddr.No && b.serviceType == p.ServiceType &&
+				(!filtered || string(b.contentType) == string(contentType)) {
+				targets = append(targets, b)
+			}
+		}
+	}
+	cancelled := false
+	for _, b := range targets {
+		if !b.isFinal() {
+			b.state, b.final = pdu.MessageStateDeleted, time.Now()
+			cancelled = true
+		}
+	}
+	if !cancelled {
+		return pdu.ErrBroadcastCancelFailed
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package smpptest
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/sujit-baniya/smpp"
+	"github.com/sujit-baniya/smpp/pdu"
+)
+
+// Fault changes how the Server answers a submit_sm, submit_multi, data_sm or
+// broadcast_sm. Delay is applied first, then the first of Drop, Unbind,
+// Malformed, Discard or Status that is set replaces the normal response.
+type Fault struct {
+	Delay     time.Duration
+	Drop      bool              // close the TCP connection without answering
+	Unbind    bool              // send an unbind instead of the response
+	Malformed bool              // answer with a response that cannot be decoded
+	Discard   bool              // never answer
+	Status    pdu.CommandStatus // answer with this command status
+}
+
+var (
+	Throttled = Fault{Status: pdu.ErrThrottled}
+	QueueFull = Fault{Status: pdu.ErrMessageQueueFull}
+	Dropped   = Fault{Drop: true}
+	Unbound   = Fault{Unbind: true}
+	Malformed = Fault{Malformed: true}
+	Discarded = Fault{Discard: true}
+)
+
+func Delayed(d time.Duration) Fault {
+	return Fault{Delay: d}
+}
+
+// Inject queues faults that are applied, in order, to the next requests.
+func (s *Server) Inject(faults ...Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = append(s.faults, faults...)
+}
+
+// Drop closes every client connection without unbinding.
+func (s *Server) Drop() {
+	s.conns.Range(func(_, conn interface{}) bool {
+		_ = conn.(net.Conn).Close()
+		return true
+	})
+}
+
+// Unbind sends an unbind to every bound session.
+func (s *Server) Unbind() {
+	for _, session := range s.SMSC.Sessions() {
+		go session.Close()
+	}
+}
+
+func (s *Server) nextFault() (fault Fault, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.faults) > 0 {
+		fault, ok = s.faults[0], true
+		s.faults = s.faults[1:]
+	}
+	return
+}
+
+// apply runs fault against a request and reports the error the handler must
+// return, or nil when the request is to be answered normally.
+func (s *Server) apply(fault Fault, session *smpp.Session, packet interface{}) error {
+	if fault.Delay > 0 && !s.sleep(fault.Delay) {
+		return smpp.ErrSkipResponse
+	}
+	switch {
+	case fault.Drop:
+		if conn, ok := s.conns.Load(session.RemoteAddr().String()); ok {
+			_ = conn.(net.Conn).Close()
+		}
+		return smpp.ErrSkipResponse
+	case fault.Unbind:
+		go session.Close()
+		return smpp.ErrSkipResponse
+	case fault.Malformed:
+		if conn, ok := s.conns.Load(session.RemoteAddr().String()); ok {
+			_, _ = conn.(net.Conn).Write(malformed(packet))
+		}
+		return smpp.ErrSkipResponse
+	case fault.Discard:
+		return smpp.ErrSkipResponse
+	case fault.Status != 0:
+		return fault.Status
+	}
+	return nil
+}
+
+// malformed builds a response to packet whose body is not NUL terminated.
+func malformed(packet interface{}) []byte {
+	var buf bytes.Buffer
+	body := []byte{0xFF, 0xFF, 0xFF, 0xFF}
+	_ = binary.Write(&buf, binary.BigEndian, pdu.Header{
+		CommandLength: uint32(16 + len(body)),
+		CommandID:     pdu.ReadCommandID(packet) | 0x80000000,
+		Sequence:      pdu.ReadSequence(packet),
+	})
+	buf.Write(body)
+	return buf.Bytes()
+}
+
+// listener records accepted connections so that faults can act on the raw
+// TCP stream.
+type listener struct {
+	net.Listener
+	conns *sync.Map
+}
+
+func (l listener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	recorded := &recordedConn{Conn: conn, key: conn.RemoteAddr().String(), conns: l.conns}
+	l.conns.Store(recorded.key, recorded)
+	return recorded, nil
+}
+
+// recordedConn forgets its connection once it is closed.
+type recordedConn struct {
+	net.Conn
+	key   string
+	conns *sync.Map
+}
+
+func (c *recordedConn) Close() error {
+	if conn, ok := c.conns.Load(c.key); ok && conn == c {
+		c.conns.Delete(c.key)
+	}
+	return c.Conn.Close()
+}
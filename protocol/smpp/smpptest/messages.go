@@ -0,0 +1,122 @@
+package smpptest
+
+import (
+	"time"
+
+	"github.com/sujit-baniya/smpp"
+	"github.com/sujit-baniya/smpp/pdu"
+)
+
+// message is the state the Server keeps about an accepted message, for
+// query_sm, cancel_sm and replace_sm.
+type message struct {
+	session string
+	from    string
+	to      string
+	state   pdu.MessageState
+	final   time.Time
+}
+
+func (m *message) isFinal() bool {
+	return !m.final.IsZero()
+}
+
+func (s *Server) accept(session *smpp.Session, id string, from, to pdu.Address) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.messages == nil {
+		s.messages = make(map[string]*message)
+	}
+	s.messages[id] = &message{session: session.ID, from: from.No, to: to.No, state: pdu.MessageStateEnroute}
+}
+
+// finish moves a message to a final state and reports whether it was still
+// pending.
+func (s *Server) finish(id string, state pdu.MessageState) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	msg, ok := s.messages[id]
+	if !ok || msg.isFinal() {
+		return false
+	}
+	msg.state, msg.final = state, time.Now()
+	return true
+}
+
+// lookup returns the message with id, or an error status when it is unknown
+// or, with RequireOrigin, was submitted on another session.
+func (s *Server) lookup(session *smpp.Session, id string) (*message, error) {
+	msg, ok := s.messages[id]
+	if !ok {
+		return nil, pdu.ErrInvalidMessageID
+	}
+	if s.RequireOrigin && msg.session != session.ID {
+		return nil, pdu.ErrInvalidBindStatus
+	}
+	return msg, nil
+}
+
+func (s *Server) querySM(session *smpp.Session, p *pdu.QuerySM) (*pdu.QuerySMResp, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	msg, err := s.lookup(session, p.MessageID)
+	if err != nil {
+		return nil, err
+	}
+	return &pdu.QuerySMResp{
+		MessageID:    p.MessageID,
+		FinalDate:    pdu.Time{Time: msg.final}.String(),
+		MessageState: msg.state,
+	}, nil
+}
+
+func (s *Server) cancelSM(session *smpp.Session, p *pdu.CancelSM) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var targets []*message
+	if p.MessageID != "" {
+		msg, err := s.lookup(session, p.MessageID)
+		if err != nil {
+			return err
+		}
+		targets = append(targets, msg)
+	} else {
+		for _, msg := range s.messages {
+			if msg.from == p.SourceAddr.No && msg.to == p.DestAddr.No {
+				targets = append(targets, msg)
+			}
+		}
+	}
+	cancelled := false
+	for _, msg := range targets {
+		if !msg.isFinal() {
+			msg.state, msg.final = pdu.MessageStateDeleted, time.Now()
+			cancelled = true
+		}
+	}
+	if !cancelled {
+		return pdu.ErrCancelFailed
+	}
+	return nil
+}
+
+func (s *Server) replaceSM(session *smpp.Session, p *pdu.ReplaceSM) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	msg, err := s.lookup(session, p.MessageID)
+	if err != nil {
+		return err
+	}
+	if msg.isFinal() {
+		return pdu.ErrReplaceFailed
+	}
+	s.replaced = append(s.replaced, p)
+	return nil
+}
+
+// Replaced returns the replace_sm PDUs accepted so far.
+func (s *Server) Replaced() []*pdu.ReplaceSM {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*pdu.ReplaceSM(nil), s.replaced...)
+}
@@ -0,0 +1,306 @@
+// Package smpptest provides an in-process SMSC for exercising the smpp
+// package without network access.
+package smpptest
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sujit-baniya/smpp"
+	"github.com/sujit-baniya/smpp/coding"
+	"github.com/sujit-baniya/smpp/pdu"
+)
+
+// MO is a mobile originated message delivered to bound receivers.
+type MO struct {
+	Delay time.Duration
+	From  string
+	To    string
+	Text  string
+}
+
+// Server is an SMSC listening on a loopback address. It answers submit_sm,
+// submit_multi, data_sm and broadcast_sm with generated message IDs and, when
+// a receipt is requested, sends a deliver_sm delivery receipt after
+// ReceiptDelay. Accepted messages can be queried, cancelled and replaced until
+// their receipt is sent, broadcasts until they are cancelled.
+// Faults queued with Inject replace the normal answer of the following
+// requests.
+type Server struct {
+	Addr            string
+	SMSC            *smpp.Server
+	ReceiptDelay    time.Duration
+	ReceiptState    func(p *pdu.SubmitSM) pdu.MessageState
+	RecipientStatus func(to string) pdu.CommandStatus              // refuses submit_multi destinations with a non-zero status
+	AreaStatus      func(area pdu.BroadcastArea) pdu.CommandStatus // fails broadcast_sm to areas with a non-zero status
+	RequireOrigin   bool                                           // answer queries, cancels and replaces only on the session that submitted the message
+	MessageID       func() string
+	Script          []MO
+	listener        net.Listener
+	conns           sync.Map
+	faults          []Fault
+	submitted       []*pdu.SubmitSM
+	multi           []*pdu.SubmitMulti
+	replaced        []*pdu.ReplaceSM
+	messages        map[string]*message
+	broadcasted     []*pdu.BroadcastSM
+	broadcasts      map[string]*broadcast
+	sequence        uint64
+	script          sync.Once
+	ctx             context.Context
+	cancel          context.CancelFunc
+	wg              sync.WaitGroup
+	mu              sync.Mutex
+}
+
+// NewServer starts and returns a new Server.
+func NewServer() *Server {
+	s := NewUnstartedServer()
+	s.Start()
+	return s
+}
+
+// NewUnstartedServer returns a new Server listening on a loopback address
+// that has not been started yet, so its fields can be changed first.
+func NewUnstartedServer() *Server {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		panic(fmt.Sprintf("smpptest: failed to listen on a port: %v", err))
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Server{
+		Addr:   l.Addr().String(),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+	s.listener = listener{Listener: l, conns: &s.conns}
+	s.SMSC = &smpp.Server{
+		Addr:     s.Addr,
+		SystemID: "smpptest",
+		Handler: smpp.ServerHandler{
+			Bound:             s.bound,
+			SubmitSM:          s.submitSM,
+			SubmitMulti:       s.submitMulti,
+			DataSM:            s.dataSM,
+			QuerySM:           s.querySM,
+			CancelSM:          s.cancelSM,
+			ReplaceSM:         s.replaceSM,
+			BroadcastSM:       s.broadcastSM,
+			QueryBroadcastSM:  s.queryBroadcastSM,
+			CancelBroadcastSM: s.cancelBroadcastSM,
+		},
+	}
+	return s
+}
+
+func (s *Server) Start() {
+	go func() { _ = s.SMSC.Serve(s.listener) }()
+}
+
+// Close cancels pending receipts and scripted messages and shuts down the SMSC.
+func (s *Server) Close() {
+	s.cancel()
+	_ = s.SMSC.Close()
+	s.wg.Wait()
+}
+
+// Submitted returns the submit_sm PDUs received so far.
+func (s *Server) Submitted() []*pdu.SubmitSM {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*pdu.SubmitSM(nil), s.submitted...)
+}
+
+// SubmittedMulti returns the submit_multi PDUs received so far.
+func (s *Server) SubmittedMulti() []*pdu.SubmitMulti {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*pdu.SubmitMulti(nil), s.multi...)
+}
+
+// DeliverMO sends a mobile originated message to a bound receiver.
+func (s *Server) DeliverMO(mo MO) error {
+	session := s.receiver("")
+	if session == nil {
+		return smpp.ErrConnectionClosed
+	}
+	parts, err := pdu.ComposeMultipartShortMessage(mo.Text, coding.BestSafeCoding(mo.Text), uint16(rand.Intn(0xFFFF)))
+	if err != nil {
+		return err
+	}
+	for _, part := range parts {
+		packet := &pdu.DeliverSM{
+			SourceAddr: pdu.Address{TON: 1, NPI: 1, No: mo.From},
+			DestAddr:   pdu.Address{TON: 1, NPI: 1, No: mo.To},
+			ESMClass:   pdu.ESMClass{UDHIndicator: part.UDHeader != nil},
+			Message:    part,
+		}
+		if err = s.deliver(session, packet); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Server) nextMessageID() string {
+	if s.MessageID != nil {
+		return s.MessageID()
+	}
+	return strconv.FormatUint(atomic.AddUint64(&s.sequence, 1), 10)
+}
+
+func (s *Server) bound(session *smpp.Session) {
+	if !session.Mode.CanReceive() || len(s.Script) == 0 {
+		return
+	}
+	s.script.Do(func() {
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			for _, mo := range s.Script {
+				if !s.sleep(mo.Delay) {
+					return
+				}
+				_ = s.DeliverMO(mo)
+			}
+		}()
+	})
+}
+
+func (s *Server) submitSM(session *smpp.Session, p *pdu.SubmitSM) (*pdu.SubmitSMResp, error) {
+	s.mu.Lock()
+	s.submitted = append(s.submitted, p)
+	s.mu.Unlock()
+	if fault, ok := s.nextFault(); ok {
+		if err := s.apply(fault, session, p); err != nil {
+			return nil, err
+		}
+	}
+	id := s.nextMessageID()
+	s.accept(session, id, p.SourceAddr, p.DestAddr)
+	if p.RegisteredDelivery.MCDeliveryReceipt == 1 {
+		state := pdu.MessageStateDelivered
+		if s.ReceiptState != nil {
+			state = s.ReceiptState(p)
+		}
+		text, _ := p.Message.Parse()
+		s.receipt(session, id, p.DestAddr, p.SourceAddr, state, text)
+	}
+	return &pdu.SubmitSMResp{MessageID: id}, nil
+}
+
+func (s *Server) submitMulti(session *smpp.Session, p *pdu.SubmitMulti) (*pdu.SubmitMultiResp, error) {
+	s.mu.Lock()
+	s.multi = append(s.multi, p)
+	s.mu.Unlock()
+	if fault, ok := s.nextFault(); ok {
+		if err := s.apply(fault, session, p); err != nil {
+			return nil, err
+		}
+	}
+	resp := &pdu.SubmitMultiResp{MessageID: s.nextMessageID()}
+	for _, address := range p.DestAddrList.Addresses {
+		if s.RecipientStatus == nil {
+			break
+		}
+		if status := s.RecipientStatus(address.No); status != 0 {
+			resp.UnsuccessfulSMEs = append(resp.UnsuccessfulSMEs, pdu.UnsuccessfulRecord{DestAddr: address, ErrorStatusCode: status})
+		}
+	}
+	return resp, nil
+}
+
+func (s *Server) dataSM(session *smpp.Session, p *pdu.DataSM) (*pdu.DataSMResp, error) {
+	if fault, ok := s.nextFault(); ok {
+		if err := s.apply(fault, session, p); err != nil {
+			return nil, err
+		}
+	}
+	id := s.nextMessageID()
+	s.accept(session, id, p.SourceAddr, p.DestAddr)
+	if p.RegisteredDelivery.MCDeliveryReceipt == 1 {
+		s.receipt(session, id, p.DestAddr, p.SourceAddr, pdu.MessageStateDelivered, "")
+	}
+	return &pdu.DataSMResp{MessageID: id}, nil
+}
+
+func (s *Server) receipt(session *smpp.Session, id string, from, to pdu.Address, state pdu.MessageState, text string) {
+	submitted := time.Now()
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		if !s.sleep(s.ReceiptDelay) || !s.finish(id, state) {
+			return
+		}
+		receiver := s.receiver(session.Auth.SystemID)
+		if receiver == nil {
+			return
+		}
+		receipt := smpp.DeliveryReceipt{
+			MessageID:  id,
+			State:      state,
+			Submitted:  1,
+			SubmitDate: submitted,
+			DoneDate:   time.Now(),
+			Err:        "000",
+			Text:       text,
+		}
+		if state == pdu.MessageStateDelivered {
+			receipt.Delivered = 1
+		}
+		if len(text) > 20 {
+			receipt.Text = text[:20]
+		}
+		var message pdu.ShortMessage
+		message.DataCoding = coding.ASCIICoding
+		message.Message = []byte(receipt.String())
+		_ = s.deliver(receiver, &pdu.DeliverSM{
+			SourceAddr: from,
+			DestAddr:   to,
+			ESMClass:   pdu.ESMClass{MessageType: 1},
+			Message:    message,
+			Tags: pdu.Tags{
+				0x001E: append([]byte(id), 0),
+				0x0427: {byte(state)},
+			},
+		})
+	}()
+}
+
+// receiver picks a bound session able to receive deliver_sm, preferring one
+// bound with systemID.
+func (s *Server) receiver(systemID string) (picked *smpp.Session) {
+	for _, session := range s.SMSC.Sessions() {
+		if !session.Mode.CanReceive() {
+			continue
+		}
+		if picked == nil || session.Auth.SystemID == systemID {
+			picked = session
+		}
+	}
+	return
+}
+
+func (s *Server) deliver(session *smpp.Session, packet *pdu.DeliverSM) error {
+	ctx, cancel := context.WithTimeout(s.ctx, 5*time.Second)
+	defer cancel()
+	_, err := session.Submit(ctx, packet)
+	return err
+}
+
+func (s *Server) sleep(d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-s.ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
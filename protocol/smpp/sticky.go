@@ -0,0 +1,70 @@
+package smpp
+
+import (
+	"context"
+	"time"
+
+	"github.com/sujit-baniya/smpp/pdu"
+)
+
+// sendSticky submits the segments of one message in order on a single
+// connection, waiting for each response before sending the next part. When a
+// part fails, the parts after it are skipped and, if the retry policy allows,
+// sending resumes from the failed part: on the connection that accepted the
+// earlier parts, failing with ErrOriginClosed once it is gone, or on any
+// connection when none was accepted. Segments already accepted when it is
+// called, by an earlier run of a queued message, are resumed the same way.
+func (m *Manager) sendSticky(ctx context.Context, result *SendResult, packets []*pdu.SubmitSM, connectionID ...string) {
+	policy := m.setting.Retry
+	attempts := make([][]Attempt, len(packets))
+	next := 0 // first part not accepted yet
+	for next < len(packets) && result.Segments[next].MessageID != "" {
+		next++
+	}
+	var err error
+	for number := 1; ; number++ {
+		var conn *Conn
+		if next == 0 {
+			conn, _ = m.GetConnection(connectionID...).(*Conn)
+		} else {
+			conn = m.pinnedConnection([]string{result.Segments[next-1].ConnectionID})
+		}
+		for i := next; i < len(packets); i++ {
+			packet := packets[i]
+			if err != nil {
+				result.Segments[i] = SegmentResult{Index: i, Packet: packet, Err: ErrSegmentSkipped, Attempts: attempts[i]}
+				continue
+			}
+			attempt := Attempt{Number: number, Start: time.Now()}
+			var resp interface{}
+			switch {
+			case conn != nil:
+				attempt.ConnectionID = conn.ID
+				resp, err = submitOn(ctx, conn, packet)
+			case next > 0:
+				err = ErrOriginClosed
+			default:
+				err = ErrNoConnection
+			}
+			attempt.Duration, attempt.Err = time.Since(attempt.Start), err
+			attempts[i] = append(attempts[i], attempt)
+			result.Segments[i], _ = segmentResult(packet, resp, attempts[i], err)
+			result.Segments[i].Index = i
+			m.remember(result.Segments[i].MessageID, result.Segments[i].ConnectionID)
+			if err = result.Segments[i].Err; err == nil {
+				next = i + 1
+			}
+		}
+		if err == nil || number >= policy.MaxAttempts || !policy.Retryable(err) {
+			break
+		}
+		if err = m.backoff(ctx, number); err != nil {
+			for i := range result.Segments {
+				if result.Segments[i].Err != nil {
+					result.Segments[i].Err = err
+				}
+			}
+			break
+		}
+	}
+}
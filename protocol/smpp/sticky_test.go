@@ -0,0 +1,46 @@
+package smpp_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sujit-baniya/smpp"
+	"github.com/sujit-baniya/smpp/smpptest"
+)
+
+func TestSendStickyResumesFailedPart(t *testing.T) {
+	server := newServer(t)
+	manager := newManager(t, server, smpp.Setting{
+		StickySegments:   true,
+		MaxConnection:    2,
+		UseAllConnection: true,
+		Retry:            smpp.RetryPolicy{MaxAttempts: 2, Backoff: smpp.Backoff{Initial: time.Millisecond}},
+	})
+	server.Inject(smpptest.Fault{}, smpptest.Throttled)
+	result, err := manager.Send(smpp.SendRequest{From: "INFO", To: "491", Message: strings.Repeat("a", 400)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var order []uint8
+	for _, packet := range server.Submitted() {
+		order = append(order, packet.Message.UDHeader.ConcatenatedHeader().Sequence)
+	}
+	if fmt.Sprint(order) != "[1 2 2 3]" {
+		t.Fatalf("parts submitted in order %v, want [1 2 2 3]", order)
+	}
+	seen := make(map[string]bool)
+	for _, segment := range result.Segments {
+		if segment.MessageID == "" || seen[segment.MessageID] {
+			t.Fatalf("message IDs %v", result.MessageIDs())
+		}
+		seen[segment.MessageID] = true
+		if segment.ConnectionID != result.Segments[0].ConnectionID {
+			t.Fatal("parts sent on different connections")
+		}
+	}
+	if n := len(result.Segments[0].Attempts); n != 1 {
+		t.Fatalf("accepted part sent %d times", n)
+	}
+}
@@ -0,0 +1,262 @@
+package smpp
+
+import (
+	"errors"
+	"time"
+
+	"github.com/rs/xid"
+	"github.com/sujit-baniya/smpp/pdu"
+)
+
+// TrackingState is a step in the lifecycle of a tracked message or segment.
+type TrackingState byte
+
+const (
+	TrackingSubmitted TrackingState = iota // sent, no response yet
+	TrackingAccepted                       // accepted by the SMSC, no final receipt yet
+	TrackingDelivered
+	TrackingFailed // refused by the SMSC or reported undeliverable
+	TrackingExpired
+)
+
+func (s TrackingState) String() string {
+	switch s {
+	case TrackingSubmitted:
+		return "submitted"
+	case TrackingAccepted:
+		return "accepted"
+	case TrackingDelivered:
+		return "delivered"
+	case TrackingFailed:
+		return "failed"
+	case TrackingExpired:
+		return "expired"
+	}
+	return "unknown"
+}
+
+// Final reports whether no later transition is expected.
+func (s TrackingState) Final() bool {
+	return s >= TrackingDelivered
+}
+
+// TrackedMessage is the lifecycle of a SendRequest. Its State rolls up the
+// states of its segments: failed or expired as soon as one segment is,
+// delivered once all are, and otherwise the least advanced segment state.
+type TrackedMessage struct {
+	ID       string
+	From     string
+	To       string
+	State    TrackingState
+	Segments []TrackedSegment
+	Created  time.Time
+	Updated  time.Time
+}
+
+// TrackedSegment is the lifecycle of one submit_sm.
+type TrackedSegment struct {
+	Index        int
+	SMSCID       string
+	State        TrackingState
+	MessageState pdu.MessageState // state of the last receipt, unknown before any
+	Err          string
+	Submitted    time.Time
+	Updated      time.Time
+}
+
+// TrackedReceipt is a receipt kept for a message ID the Store does not know
+// yet, either because it arrived before the submit_sm_resp was recorded or
+// because the message was not tracked.
+type TrackedReceipt struct {
+	SMSCID       string
+	MessageState pdu.MessageState
+	Err          string
+	Final        bool
+	Received     time.Time
+}
+
+// Store keeps tracked messages, looked up by their ID or by the message ID
+// the SMSC assigned to one of their segments. Orphan receipts are kept up to
+// maxOrphans, the oldest dropped first, and PruneOrphans removes those
+// received before a given time.
+type Store interface {
+	Save(msg *TrackedMessage) error
+	Load(id string) (*TrackedMessage, error)
+	LoadBySMSCID(smscID string) (*TrackedMessage, error)
+	SaveOrphan(receipt TrackedReceipt) error
+	TakeOrphan(smscID string) (TrackedReceipt, error)
+	Orphans() ([]TrackedReceipt, error)
+	PruneOrphans(before time.Time) (int, error)
+}
+
+// maxOrphans bounds the orphan receipts a Store keeps, like the early
+// receipts kept for SendAndAwaitDelivery.
+const maxOrphans = maxEarlyReceipts
+
+// rollup sets the state of msg from its segments.
+func (msg *TrackedMessage) rollup() {
+	if len(msg.Segments) == 0 {
+		return
+	}
+	state := TrackingDelivered
+	for _, segment := range msg.Segments {
+		switch {
+		case segment.State == TrackingFailed || segment.State == TrackingExpired:
+			if state != TrackingFailed {
+				state = segment.State
+			}
+		case state.Final() && state != TrackingDelivered:
+		case segment.State < state:
+			state = segment.State
+		}
+	}
+	msg.State = state
+}
+
+// receiptState maps the state of a final receipt to a tracking state.
+func receiptState(state pdu.MessageState) TrackingState {
+	switch state {
+	case pdu.MessageStateDelivered, pdu.MessageStateAccepted:
+		return TrackingDelivered
+	case pdu.MessageStateExpired:
+		return TrackingExpired
+	}
+	return TrackingFailed
+}
+
+// apply records receipt on the segment with its SMSC ID. A segment in a
+// final state keeps it: later receipts for it are duplicates.
+func (msg *TrackedMessage) apply(receipt TrackedReceipt) bool {
+	for i := range msg.Segments {
+		segment := &msg.Segments[i]
+		if segment.SMSCID != receipt.SMSCID || segment.State.Final() {
+			continue
+		}
+		segment.MessageState, segment.Updated = receipt.MessageState, receipt.Received
+		if receipt.Err != "" {
+			segment.Err = receipt.Err
+		}
+		if receipt.Final {
+			segment.State = receiptState(receipt.MessageState)
+		} else if segment.State == TrackingSubmitted {
+			segment.State = TrackingAccepted
+		}
+		msg.Updated = receipt.Received
+		msg.rollup()
+		return true
+	}
+	return false
+}
+
+// trackSubmit records req as submitted before its segments are sent and
+// returns its tracking ID.
+func (m *Manager) trackSubmit(id string, req SendRequest, segments int) string {
+	store := m.setting.Store
+	if store == nil {
+		return ""
+	}
+	if id == "" {
+		id = xid.New().String()
+	}
+	m.tracking.Lock()
+	defer m.tracking.Unlock()
+	if _, err := store.Load(id); err == nil {
+		// a queued message sent again keeps its progress
+		return id
+	}
+	now := time.Now()
+	msg := &TrackedMessage{ID: id, From: req.From, To: req.To, Created: now, Updated: now}
+	for i := 0; i < segments; i++ {
+		msg.Segments = append(msg.Segments, TrackedSegment{
+			Index:        i,
+			MessageState: pdu.MessageStateUnknown,
+			Submitted:    now,
+			Updated:      now,
+		})
+	}
+	msg.rollup()
+	_ = store.Save(msg)
+	return id
+}
+
+// trackResult records the responses to the segments of a tracked message and
+// the receipts that arrived before them.
+func (m *Manager) trackResult(result *SendResult) {
+	store := m.setting.Store
+	if store == nil || result.TrackingID == "" {
+		return
+	}
+	m.tracking.Lock()
+	defer m.tracking.Unlock()
+	msg, err := store.Load(result.TrackingID)
+	if err != nil {
+		return
+	}
+	now := time.Now()
+	for _, segment := range result.Segments {
+		if segment.Index >= len(msg.Segments) {
+			continue
+		}
+		tracked := &msg.Segments[segment.Index]
+		if segment.MessageID != "" && tracked.SMSCID == segment.MessageID {
+			// accepted by an earlier run of a queued message
+			continue
+		}
+		tracked.SMSCID, tracked.Updated = segment.MessageID, now
+		if !segment.Submitted.IsZero() {
+			tracked.Submitted = segment.Submitted
+		}
+		if segment.Err != nil {
+			tracked.State, tracked.Err = TrackingFailed, segment.Err.Error()
+		} else {
+			tracked.State, tracked.Err = TrackingAccepted, ""
+		}
+	}
+	msg.Updated = now
+	msg.rollup()
+	for _, segment := range msg.Segments {
+		if segment.SMSCID == "" {
+			continue
+		}
+		if receipt, err := store.TakeOrphan(segment.SMSCID); err == nil {
+			msg.apply(receipt)
+		}
+	}
+	_ = store.Save(msg)
+}
+
+// trackReceipt records a delivery receipt, as an orphan when its message is
+// not known.
+func (m *Manager) trackReceipt(r *DeliveryReceipt) error {
+	store := m.setting.Store
+	receipt := TrackedReceipt{SMSCID: r.MessageID, MessageState: r.State, Err: r.Err, Final: r.Final(), Received: time.Now()}
+	m.tracking.Lock()
+	defer m.tracking.Unlock()
+	msg, err := store.LoadBySMSCID(r.MessageID)
+	if errors.Is(err, ErrNotTracked) {
+		return store.SaveOrphan(receipt)
+	} else if err != nil {
+		return err
+	}
+	if !msg.apply(receipt) {
+		return nil
+	}
+	return store.Save(msg)
+}
+
+// onDeliveryReceipt is the receipt handler of the connections: it tracks the
+// receipt, then passes it to Setting.OnDeliveryReceipt.
+func (m *Manager) onDeliveryReceipt() func(receipt *DeliveryReceipt) error {
+	if m.setting.Store == nil {
+		return m.setting.OnDeliveryReceipt
+	}
+	return func(receipt *DeliveryReceipt) error {
+		if err := m.trackReceipt(receipt); err != nil {
+			return err
+		}
+		if m.setting.OnDeliveryReceipt != nil {
+			return m.setting.OnDeliveryReceipt(receipt)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,164 @@
+package smpp
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryStore is a Store keeping messages in memory. It forgets the oldest
+// messages beyond its limit, and the oldest orphan receipts beyond the same
+// limit or, without one, maxOrphans.
+type MemoryStore struct {
+	limit       int
+	messages    map[string]*TrackedMessage
+	order       []string
+	smscIDs     map[string]string
+	orphans     map[string]TrackedReceipt
+	orphanOrder []TrackedReceipt
+	mu          sync.Mutex
+}
+
+// NewMemoryStore returns a MemoryStore holding up to limit messages, or any
+// number if limit is zero.
+func NewMemoryStore(limit int) *MemoryStore {
+	return &MemoryStore{
+		limit:    limit,
+		messages: make(map[string]*TrackedMessage),
+		smscIDs:  make(map[string]string),
+		orphans:  make(map[string]TrackedReceipt),
+	}
+}
+
+func (s *MemoryStore) Save(msg *TrackedMessage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.messages[msg.ID]; !ok {
+		s.order = append(s.order, msg.ID)
+	}
+	s.messages[msg.ID] = copyTracked(msg)
+	for _, segment := range msg.Segments {
+		if segment.SMSCID != "" {
+			s.smscIDs[segment.SMSCID] = msg.ID
+		}
+	}
+	for s.limit > 0 && len(s.order) > s.limit {
+		s.forget(s.order[0])
+		s.order = s.order[1:]
+	}
+	return nil
+}
+
+func (s *MemoryStore) forget(id string) {
+	if msg, ok := s.messages[id]; ok {
+		for _, segment := range msg.Segments {
+			delete(s.smscIDs, segment.SMSCID)
+		}
+		delete(s.messages, id)
+	}
+}
+
+func (s *MemoryStore) Load(id string) (*TrackedMessage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	msg, ok := s.messages[id]
+	if !ok {
+		return nil, ErrNotTracked
+	}
+	return copyTracked(msg), nil
+}
+
+func (s *MemoryStore) LoadBySMSCID(smscID string) (*TrackedMessage, error) {
+	s.mu.Lock()
+	id, ok := s.smscIDs[smscID]
+	s.mu.Unlock()
+	if !ok {
+		return nil, ErrNotTracked
+	}
+	return s.Load(id)
+}
+
+func (s *MemoryStore) SaveOrphan(receipt TrackedReceipt) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if previous, ok := s.orphans[receipt.SMSCID]; ok && previous.Final {
+		return nil
+	}
+	s.orphans[receipt.SMSCID] = receipt
+	s.orphanOrder = append(s.orphanOrder, receipt)
+	limit := s.limit
+	if limit <= 0 {
+		limit = maxOrphans
+	}
+	for len(s.orphans) > limit {
+		s.dropOrphan(s.orphanOrder[0])
+		s.orphanOrder = s.orphanOrder[1:]
+	}
+	if len(s.orphanOrder) > 2*len(s.orphans)+64 {
+		order := s.orphanOrder[:0]
+		for _, receipt := range s.orphanOrder {
+			if current, ok := s.orphans[receipt.SMSCID]; ok && current.Received.Equal(receipt.Received) {
+				order = append(order, receipt)
+			}
+		}
+		s.orphanOrder = order
+	}
+	return nil
+}
+
+// dropOrphan removes receipt unless it was replaced by a later one.
+func (s *MemoryStore) dropOrphan(receipt TrackedReceipt) {
+	if current, ok := s.orphans[receipt.SMSCID]; ok && current.Received.Equal(receipt.Received) {
+		delete(s.orphans, receipt.SMSCID)
+	}
+}
+
+func (s *MemoryStore) TakeOrphan(smscID string) (TrackedReceipt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	receipt, ok := s.orphans[smscID]
+	if !ok {
+		return receipt, ErrNotTracked
+	}
+	delete(s.orphans, smscID)
+	return receipt, nil
+}
+
+// PruneOrphans removes the orphan receipts received before the given time and
+// returns how many there were.
+func (s *MemoryStore) PruneOrphans(before time.Time) (n int, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, receipt := range s.orphans {
+		if receipt.Received.Before(before) {
+			delete(s.orphans, id)
+			n++
+		}
+	}
+	order := s.orphanOrder[:0]
+	for _, receipt := range s.orphanOrder {
+		if !receipt.Received.Before(before) {
+			order = append(order, receipt)
+		}
+	}
+	s.orphanOrder = order
+	return n, nil
+}
+
+// Orphans returns the receipts of unknown messages, oldest first.
+func (s *MemoryStore) Orphans() ([]TrackedReceipt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	orphans := make([]TrackedReceipt, 0, len(s.orphans))
+	for _, receipt := range s.orphans {
+		orphans = append(orphans, receipt)
+	}
+	sort.Slice(orphans, func(i, j int) bool { return orphans[i].Received.Before(orphans[j].Received) })
+	return orphans, nil
+}
+
+func copyTracked(msg *TrackedMessage) *TrackedMessage {
+	c := *msg
+	c.Segments = append([]TrackedSegment(nil), msg.Segments...)
+	return &c
+}
@@ -0,0 +1,212 @@
+package smpp
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/sujit-baniya/smpp/pdu"
+)
+
+// SQLiteStore is a Store in a SQLite database. The application opens the
+// database with the driver of its choice, such as modernc.org/sqlite or
+// github.com/mattn/go-sqlite3.
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS smpp_messages (
+	id          TEXT PRIMARY KEY,
+	source      TEXT NOT NULL,
+	destination TEXT NOT NULL,
+	state       INTEGER NOT NULL,
+	created     INTEGER NOT NULL,
+	updated     INTEGER NOT NULL
+);
+CREATE TABLE IF NOT EXISTS smpp_segments (
+	message_id    TEXT NOT NULL REFERENCES smpp_messages (id) ON DELETE CASCADE,
+	idx           INTEGER NOT NULL,
+	smsc_id       TEXT NOT NULL,
+	state         INTEGER NOT NULL,
+	message_state INTEGER NOT NULL,
+	error         TEXT NOT NULL,
+	submitted     INTEGER NOT NULL,
+	updated       INTEGER NOT NULL,
+	PRIMARY KEY (message_id, idx)
+);
+CREATE INDEX IF NOT EXISTS smpp_segments_smsc_id ON smpp_segments (smsc_id);
+CREATE TABLE IF NOT EXISTS smpp_orphans (
+	smsc_id       TEXT PRIMARY KEY,
+	message_state INTEGER NOT NULL,
+	error         TEXT NOT NULL,
+	final         INTEGER NOT NULL,
+	received      INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS smpp_orphans_received ON smpp_orphans (received);`
+
+// NewSQLiteStore creates the tables of the store in db if needed.
+func NewSQLiteStore(db *sql.DB) (*SQLiteStore, error) {
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		return nil, err
+	}
+	return &SQLiteStore{db: db}, nil
+}
+
+func (s *SQLiteStore) Save(msg *TrackedMessage) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+	_, err = tx.Exec(
+		`INSERT OR REPLACE INTO smpp_messages (id, source, destination, state, created, updated) VALUES (?, ?, ?, ?, ?, ?)`,
+		msg.ID, msg.From, msg.To, msg.State, unixNano(msg.Created), unixNano(msg.Updated),
+	)
+	if err == nil {
+		_, err = tx.Exec(`DELETE FROM smpp_segments WHERE message_id = ?`, msg.ID)
+	}
+	for _, segment := range msg.Segments {
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(
+			`INSERT INTO smpp_segments (message_id, idx, smsc_id, state, message_state, error, submitted, updated) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+			msg.ID, segment.Index, segment.SMSCID, segment.State, segment.MessageState, segment.Err,
+			unixNano(segment.Submitted), unixNano(segment.Updated),
+		)
+	}
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *SQLiteStore) Load(id string) (*TrackedMessage, error) {
+	msg := &TrackedMessage{ID: id}
+	var created, updated int64
+	err := s.db.QueryRow(
+		`SELECT source, destination, state, created, updated FROM smpp_messages WHERE id = ?`, id,
+	).Scan(&msg.From, &msg.To, &msg.State, &created, &updated)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotTracked
+	} else if err != nil {
+		return nil, err
+	}
+	msg.Created, msg.Updated = fromUnixNano(created), fromUnixNano(updated)
+	rows, err := s.db.Query(
+		`SELECT idx, smsc_id, state, message_state, error, submitted, updated FROM smpp_segments WHERE message_id = ? ORDER BY idx`, id,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	for rows.Next() {
+		var segment TrackedSegment
+		var state byte
+		if err = rows.Scan(&segment.Index, &segment.SMSCID, &segment.State, &state, &segment.Err, &created, &updated); err != nil {
+			return nil, err
+		}
+		segment.MessageState = pdu.MessageState(state)
+		segment.Submitted, segment.Updated = fromUnixNano(created), fromUnixNano(updated)
+		msg.Segments = append(msg.Segments, segment)
+	}
+	return msg, rows.Err()
+}
+
+func (s *SQLiteStore) LoadBySMSCID(smscID string) (*TrackedMessage, error) {
+	var id string
+	err := s.db.QueryRow(
+		`SELECT message_id FROM smpp_segments WHERE smsc_id = ? ORDER BY submitted DESC LIMIT 1`, smscID,
+	).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotTracked
+	} else if err != nil {
+		return nil, err
+	}
+	return s.Load(id)
+}
+
+func (s *SQLiteStore) SaveOrphan(receipt TrackedReceipt) error {
+	_, err := s.db.Exec(
+		`INSERT INTO smpp_orphans (smsc_id, message_state, error, final, received) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (smsc_id) DO UPDATE SET message_state = excluded.message_state, error = excluded.error,
+		final = excluded.final, received = excluded.received WHERE NOT smpp_orphans.final`,
+		receipt.SMSCID, receipt.MessageState, receipt.Err, receipt.Final, unixNano(receipt.Received),
+	)
+	if err == nil {
+		_, err = s.db.Exec(
+			`DELETE FROM smpp_orphans WHERE smsc_id IN (SELECT smsc_id FROM smpp_orphans ORDER BY received DESC LIMIT -1 OFFSET ?)`,
+			maxOrphans,
+		)
+	}
+	return err
+}
+
+// PruneOrphans removes the orphan receipts received before the given time and
+// returns how many there were.
+func (s *SQLiteStore) PruneOrphans(before time.Time) (int, error) {
+	result, err := s.db.Exec(`DELETE FROM smpp_orphans WHERE received < ?`, unixNano(before))
+	if err != nil {
+		return 0, err
+	}
+	n, err := result.RowsAffected()
+	return int(n), err
+}
+
+func (s *SQLiteStore) TakeOrphan(smscID string) (receipt TrackedReceipt, err error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+	var state byte
+	var received int64
+	err = tx.QueryRow(
+		`SELECT message_state, error, final, received FROM smpp_orphans WHERE smsc_id = ?`, smscID,
+	).Scan(&state, &receipt.Err, &receipt.Final, &received)
+	if errors.Is(err, sql.ErrNoRows) {
+		return receipt, ErrNotTracked
+	} else if err != nil {
+		return
+	}
+	if _, err = tx.Exec(`DELETE FROM smpp_orphans WHERE smsc_id = ?`, smscID); err != nil {
+		return
+	}
+	receipt.SMSCID, receipt.MessageState, receipt.Received = smscID, pdu.MessageState(state), fromUnixNano(received)
+	return receipt, tx.Commit()
+}
+
+// Orphans returns the receipts of unknown messages, oldest first.
+func (s *SQLiteStore) Orphans() (orphans []TrackedReceipt, err error) {
+	rows, err := s.db.Query(`SELECT smsc_id, message_state, error, final, received FROM smpp_orphans ORDER BY received`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	for rows.Next() {
+		var receipt TrackedReceipt
+		var state byte
+		var received int64
+		if err = rows.Scan(&receipt.SMSCID, &state, &receipt.Err, &receipt.Final, &received); err != nil {
+			return nil, err
+		}
+		receipt.MessageState, receipt.Received = pdu.MessageState(state), fromUnixNano(received)
+		orphans = append(orphans, receipt)
+	}
+	return orphans, rows.Err()
+}
+
+func unixNano(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+func fromUnixNano(n int64) time.Time {
+	if n == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, n)
+}
@@ -0,0 +1,164 @@
+package smpp_test
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	_ "modernc.org/sqlite"
+
+	"github.com/sujit-baniya/smpp"
+	"github.com/sujit-baniya/smpp/pdu"
+)
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, smpp.NewMemoryStore(0))
+}
+
+// TestSQLiteStore runs against modernc.org/sqlite, a pure Go driver only the
+// tests depend on.
+func TestSQLiteStore(t *testing.T) {
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "track.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	store, err := smpp.NewSQLiteStore(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testStore(t, store)
+}
+
+func testStore(t *testing.T, store smpp.Store) {
+	if _, err := store.Load("unknown"); !errors.Is(err, smpp.ErrNotTracked) {
+		t.Fatalf("load of an unknown message: %v", err)
+	}
+	now := time.Now()
+	msg := &smpp.TrackedMessage{
+		ID: "m1", From: "INFO", To: "491", State: smpp.TrackingAccepted, Created: now, Updated: now,
+		Segments: []smpp.TrackedSegment{
+			{Index: 0, SMSCID: "s1", State: smpp.TrackingDelivered, MessageState: pdu.MessageStateDelivered, Submitted: now, Updated: now},
+			{Index: 1, SMSCID: "s2", State: smpp.TrackingAccepted, MessageState: pdu.MessageStateUnknown, Submitted: now, Updated: now},
+		},
+	}
+	if err := store.Save(msg); err != nil {
+		t.Fatal(err)
+	}
+	msg.Segments[1].State, msg.State = smpp.TrackingFailed, smpp.TrackingFailed
+	msg.Segments[1].Err = "034"
+	if err := store.Save(msg); err != nil {
+		t.Fatal(err)
+	}
+	got, err := store.LoadBySMSCID("s2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != "m1" || got.To != "491" || got.State != smpp.TrackingFailed || len(got.Segments) != 2 {
+		t.Fatalf("loaded %+v", got)
+	}
+	if segment := got.Segments[1]; segment.SMSCID != "s2" || segment.State != smpp.TrackingFailed || segment.Err != "034" {
+		t.Fatalf("loaded segment %+v", segment)
+	}
+	if !got.Created.Equal(now) {
+		t.Fatalf("created %v, want %v", got.Created, now)
+	}
+
+	receipt := func(id string, state pdu.MessageState, final bool, age time.Duration) smpp.TrackedReceipt {
+		return smpp.TrackedReceipt{SMSCID: id, MessageState: state, Final: final, Received: now.Add(-age)}
+	}
+	for _, orphan := range []smpp.TrackedReceipt{
+		receipt("o1", pdu.MessageStateEnroute, false, 3*time.Hour),
+		receipt("o1", pdu.MessageStateDelivered, true, 2*time.Hour),
+		receipt("o1", pdu.MessageStateEnroute, false, time.Hour),
+		receipt("o2", pdu.MessageStateUndeliverable, true, time.Hour),
+		receipt("o3", pdu.MessageStateEnroute, false, time.Minute),
+	} {
+		if err = store.SaveOrphan(orphan); err != nil {
+			t.Fatal(err)
+		}
+	}
+	orphans, err := store.Orphans()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(orphans) != 3 || orphans[0].SMSCID != "o1" || orphans[2].SMSCID != "o3" {
+		t.Fatalf("orphans %+v", orphans)
+	}
+	taken, err := store.TakeOrphan("o1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !taken.Final || taken.MessageState != pdu.MessageStateDelivered {
+		t.Fatalf("final receipt overwritten by %+v", taken)
+	}
+	if _, err = store.TakeOrphan("o1"); !errors.Is(err, smpp.ErrNotTracked) {
+		t.Fatalf("orphan taken twice: %v", err)
+	}
+	n, err := store.PruneOrphans(now.Add(-30 * time.Minute))
+	if err != nil || n != 1 {
+		t.Fatalf("pruned %d, %v; want 1", n, err)
+	}
+	if orphans, _ = store.Orphans(); len(orphans) != 1 || orphans[0].SMSCID != "o3" {
+		t.Fatalf("orphans after pruning %+v", orphans)
+	}
+}
+
+func TestMemoryStoreLimit(t *testing.T) {
+	store := smpp.NewMemoryStore(2)
+	for i := 0; i < 3; i++ {
+		id := fmt.Sprint(i)
+		_ = store.Save(&smpp.TrackedMessage{ID: id, Segments: []smpp.TrackedSegment{{SMSCID: "s" + id}}})
+		_ = store.SaveOrphan(smpp.TrackedReceipt{SMSCID: "o" + id, Received: time.Now()})
+	}
+	if _, err := store.LoadBySMSCID("s0"); !errors.Is(err, smpp.ErrNotTracked) {
+		t.Fatalf("oldest message kept: %v", err)
+	}
+	if _, err := store.Load("2"); err != nil {
+		t.Fatal(err)
+	}
+	orphans, _ := store.Orphans()
+	if len(orphans) != 2 || orphans[0].SMSCID != "o1" {
+		t.Fatalf("orphans %+v", orphans)
+	}
+}
+
+func TestTrackingRollup(t *testing.T) {
+	server := newServer(t)
+	store := smpp.NewMemoryStore(0)
+	manager := newManager(t, server, smpp.Setting{Store: store})
+
+	result, err := manager.Send(smpp.SendRequest{From: "INFO", To: "491", Message: "tracked"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	eventually(t, func() bool {
+		msg, err := store.Load(result.TrackingID)
+		return err == nil && msg.State == smpp.TrackingDelivered
+	})
+
+	server.ReceiptState = func(p *pdu.SubmitSM) pdu.MessageState {
+		if header := p.Message.UDHeader.ConcatenatedHeader(); header != nil && header.Sequence == 2 {
+			return pdu.MessageStateUndeliverable
+		}
+		return pdu.MessageStateDelivered
+	}
+	result, err = manager.Send(smpp.SendRequest{From: "INFO", To: "491", Message: fmt.Sprintf("%0200d", 0)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	eventually(t, func() bool {
+		msg, err := store.Load(result.TrackingID)
+		return err == nil && len(msg.Segments) == 2 && msg.Segments[0].State.Final() && msg.Segments[1].State.Final()
+	})
+	msg, _ := store.Load(result.TrackingID)
+	if msg.State != smpp.TrackingFailed || msg.Segments[0].State != smpp.TrackingDelivered {
+		t.Fatalf("tracked %+v", msg)
+	}
+	if orphans, _ := store.Orphans(); len(orphans) != 0 {
+		t.Fatalf("receipts of tracked messages kept as orphans: %+v", orphans)
+	}
+}
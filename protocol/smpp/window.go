@@ -0,0 +1,70 @@
+package smpp
+
+import (
+	"context"
+)
+
+type noWaitKey struct{}
+
+// NoWait returns a context under which a full window fails with
+// ErrWindowFull instead of blocking until a slot is released.
+func NoWait(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noWaitKey{}, true)
+}
+
+// Window limits the number of requests awaiting a response on a connection.
+type Window struct {
+	slots chan struct{}
+}
+
+func NewWindow(size int) *Window {
+	return &Window{slots: make(chan struct{}, size)}
+}
+
+// Acquire takes a slot, blocking while the window is full until ctx ends.
+func (w *Window) Acquire(ctx context.Context) error {
+	select {
+	case w.slots <- struct{}{}:
+		return nil
+	default:
+	}
+	if noWait, _ := ctx.Value(noWaitKey{}).(bool); noWait {
+		return ErrWindowFull
+	}
+	select {
+	case w.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (w *Window) Release() {
+	select {
+	case <-w.slots:
+	default:
+	}
+}
+
+// Len returns the number of requests currently in flight.
+func (w *Window) Len() int {
+	return len(w.slots)
+}
+
+// Cap returns the window size.
+func (w *Window) Cap() int {
+	return cap(w.slots)
+}
+
+// WindowUsage returns the number of requests in flight per connection ID.
+func (m *Manager) WindowUsage() map[string]int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	usage := make(map[string]int, len(m.connections))
+	for id, conn := range m.connections {
+		if conn.Window != nil {
+			usage[id] = conn.Window.Len()
+		}
+	}
+	return usage
+}
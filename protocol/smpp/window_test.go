@@ -0,0 +1,42 @@
+package smpp_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sujit-baniya/smpp"
+	"github.com/sujit-baniya/smpp/smpptest"
+)
+
+func TestSendWindow(t *testing.T) {
+	server := newServer(t)
+	manager := newManager(t, server, smpp.Setting{WindowSize: 2})
+	server.Inject(smpptest.Delayed(300*time.Millisecond), smpptest.Delayed(300*time.Millisecond))
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := manager.Send(smpp.SendRequest{From: "INFO", To: "491", Message: "slow"}); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	eventually(t, func() bool {
+		for _, n := range manager.WindowUsage() {
+			return n == 2
+		}
+		return false
+	})
+	_, err := manager.SendContext(smpp.NoWait(context.Background()), smpp.SendRequest{From: "INFO", To: "491", Message: "fast"})
+	if !errors.Is(err, smpp.ErrWindowFull) {
+		t.Fatalf("send on a full window: %v", err)
+	}
+	wg.Wait()
+	if _, err = manager.Send(smpp.SendRequest{From: "INFO", To: "491", Message: "again"}); err != nil {
+		t.Fatal(err)
+	}
+}